@@ -3,7 +3,9 @@ module github.com/jorgenhanssen/go-genetic-mdvrp
 go 1.16
 
 require (
-	github.com/jinzhu/copier v0.2.8 // indirect
-	github.com/tfriedel6/canvas v0.12.1 // indirect
-	gopkg.in/go-playground/colors.v1 v1.2.0 // indirect
+	github.com/jinzhu/copier v0.2.8
+	github.com/tfriedel6/canvas v0.12.1
+	golang.org/x/image v0.0.0-20200119044424-58c23975cae1
+	gopkg.in/go-playground/colors.v1 v1.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
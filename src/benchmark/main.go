@@ -0,0 +1,231 @@
+// Benchmark runs the solver over every instance in a problem
+// directory and reports the distance and gap to the best-known
+// solution of each instance. Only feasible solutions count, the
+// same as for the gap reported by the solver.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/problem"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+var (
+	dir          = flag.String("dir", "problems", "directory of problem instances")
+	seeds        = flag.Int("seeds", 3, "number of seeded runs per instance")
+	generations  = flag.Int("generations", 500, "generation budget per run (0 for no limit)")
+	timeout      = flag.Duration("time", 0, "time budget per run (0 for no limit)")
+	baselinePath = flag.String("baseline", "", "fail if any gap regresses beyond -threshold compared to this baseline")
+	savePath     = flag.String("save-baseline", "", "save the gaps of this benchmark as a baseline")
+	threshold    = flag.Float64("threshold", 1, "allowed gap regression in percentage points")
+)
+
+// result is the outcome of all runs of an instance.
+// Costs are the distances of the feasible runs.
+type result struct {
+	instance   string
+	costs      []float64
+	infeasible int
+	runtimes   []time.Duration
+}
+
+func main() {
+	flag.Parse()
+
+	if *generations == 0 && *timeout == 0 {
+		fmt.Fprintln(os.Stderr, "A generation or time budget is required")
+		os.Exit(2)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(*dir, "p[0-9]*"))
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(paths)
+
+	results := []result{}
+	for _, path := range paths {
+		res, err := benchmark(path)
+		if err != nil {
+			panic(err)
+		}
+		results = append(results, res)
+	}
+
+	gaps := report(results)
+
+	if *savePath != "" {
+		if err := saveBaseline(*savePath, gaps); err != nil {
+			panic(err)
+		}
+	}
+
+	if *baselinePath != "" {
+		baseline, err := loadBaseline(*baselinePath)
+		if err != nil {
+			panic(err)
+		}
+		if regressions := compare(baseline, gaps, results); regressions > 0 {
+			fmt.Printf("\n%d instance(s) regressed beyond %.2f percentage points\n", regressions, *threshold)
+			os.Exit(1)
+		}
+	}
+}
+
+// benchmark solves the instance once per seed.
+func benchmark(path string) (res result, err error) {
+	res.instance = problem.InstanceName(path)
+
+	instance, err := problem.LoadInstance(path)
+	if err != nil {
+		return res, err
+	}
+
+	for seed := 1; seed <= *seeds; seed++ {
+		cfg := solver.DefaultConfig()
+		cfg.Depots = instance.Depots
+		cfg.Customers = instance.Customers
+		cfg.Instance = res.instance
+		cfg.ProblemType = instance.Type
+		cfg.Metric = instance.Metric
+		cfg.Seed = int64(seed)
+
+		// Threads replace agents in an unpredictable order,
+		// so only single-threaded runs are reproducible.
+		cfg.NumCPUs = 1
+
+		slvr, err := solver.NewSolver(cfg)
+		if err != nil {
+			return res, err
+		}
+
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if *timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, *timeout)
+		}
+		info := slvr.Solve(ctx, solver.EndCondition{Generations: *generations})
+		cancel()
+
+		fmt.Fprintf(os.Stderr, "%s seed %d: %v\n", res.instance, seed, info.BestAgent.Fitness)
+
+		if info.BestAgent.Fitness.IsFeasible() {
+			res.costs = append(res.costs, info.BestAgent.Fitness.Distance)
+		} else {
+			res.infeasible++
+		}
+		res.runtimes = append(res.runtimes, info.Elapsed)
+	}
+
+	return res, nil
+}
+
+// report prints a table of the results and returns
+// the gap of the best cost of each known instance.
+func report(results []result) map[string]float64 {
+	gaps := map[string]float64{}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "instance\tbks\tbest\tmean\tstd\tinfeasible\truntime\tgap (%)\t")
+	for _, res := range results {
+		runtime := time.Duration(0)
+		for _, r := range res.runtimes {
+			runtime += r
+		}
+		runtime /= time.Duration(len(res.runtimes))
+
+		bks, gap := "-", "-"
+		if cost, ok := problem.BestKnown[res.instance]; ok {
+			bks = fmt.Sprintf("%.2f", cost)
+		}
+		best, mean, std := "-", "-", "-"
+		if len(res.costs) > 0 {
+			b, m, s := stats(res.costs)
+			best, mean, std = fmt.Sprintf("%.2f", b), fmt.Sprintf("%.2f", m), fmt.Sprintf("%.2f", s)
+			if g, ok := problem.Gap(res.instance, b); ok {
+				gaps[res.instance] = g
+				gap = fmt.Sprintf("%.2f", g)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%v\t%s\t\n",
+			res.instance, bks, best, mean, std, res.infeasible, runtime.Round(time.Millisecond), gap)
+	}
+	w.Flush()
+
+	return gaps
+}
+
+// stats returns the minimum, mean and standard deviation of the values.
+func stats(values []float64) (min, mean, std float64) {
+	min = math.Inf(1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		std += math.Pow(v-mean, 2)
+	}
+	std = math.Sqrt(std / float64(len(values)))
+
+	return
+}
+
+// compare prints and counts the instances where the gap
+// regressed beyond the threshold compared to the baseline.
+// Instances in the baseline without a feasible run regressed.
+func compare(baseline, gaps map[string]float64, results []result) (regressions int) {
+	for _, res := range results {
+		instance := res.instance
+		base, ok := baseline[instance]
+		if !ok {
+			continue
+		}
+		if _, ok := gaps[instance]; !ok {
+			fmt.Printf("%s: no feasible solution, baseline gap %.2f%%\n", instance, base)
+			regressions++
+			continue
+		}
+		if gaps[instance]-base > *threshold {
+			fmt.Printf("%s: gap %.2f%% regressed from baseline %.2f%%\n", instance, gaps[instance], base)
+			regressions++
+		}
+	}
+
+	return
+}
+
+// saveBaseline writes the gaps as a JSON baseline.
+func saveBaseline(filePath string, gaps map[string]float64) error {
+	data, err := json.MarshalIndent(gaps, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+// loadBaseline reads a baseline written by saveBaseline.
+func loadBaseline(filePath string) (map[string]float64, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := map[string]float64{}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, err
+	}
+	return baseline, nil
+}
@@ -0,0 +1,47 @@
+// Convert converts problem instances to JSON or YAML documents.
+//
+//	go run ./src/convert problems/p01 p01.json
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/problem"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "Usage: convert <problem> <output.json|output.yaml>")
+		os.Exit(2)
+	}
+	input, output := os.Args[1], os.Args[2]
+
+	instance, err := problem.LoadInstance(input)
+	if err != nil {
+		panic(err)
+	}
+	doc, err := problem.NewDocument(problem.InstanceName(input), instance)
+	if err != nil {
+		panic(err)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	switch filepath.Ext(output) {
+	case ".json":
+		err = doc.WriteJSON(file)
+	case ".yaml", ".yml":
+		err = doc.WriteYAML(file)
+	default:
+		err = fmt.Errorf("Unknown output format %q", filepath.Ext(output))
+	}
+	if err != nil {
+		panic(err)
+	}
+}
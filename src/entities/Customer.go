@@ -3,7 +3,7 @@ package entities
 import (
 	"fmt"
 	"math/rand"
-	"reflect"
+	"sort"
 )
 
 // Customer describes a customer objects.
@@ -16,6 +16,9 @@ type Customer struct {
 	// ID of the customer.
 	ID int
 
+	// Name of the customer, if any.
+	Name string
+
 	// ServiceDuration is how long a visit will
 	// take for this customer.
 	// This is linked with depots' max route duration.
@@ -25,6 +28,21 @@ type Customer struct {
 	// for this customer.
 	// This is linked with depots' max vehicle load.
 	Demand float64
+
+	// Frequency is how many times the customer is
+	// visited over the planning horizon (PVRP).
+	Frequency int
+
+	// Combinations are the allowed visit combinations.
+	// For periodic problems each combination is a bit set of
+	// days (bit i set means a visit on day i). For site-dependent
+	// problems the combinations are the allowed vehicle types.
+	Combinations []int
+
+	// TimeWindow is the earliest and latest start of service
+	// at the customer. Vehicles arriving early wait for the
+	// window to open. It is nil if the customer has no time window.
+	TimeWindow *TimeWindow
 }
 
 // String returns the stringified customer.
@@ -46,10 +64,21 @@ func (c *Customer) GetPosition() (X, Y float64) {
 // The key is the customer's ID.
 type Customers map[int]*Customer
 
-// RandomSelect returns a random customer and their ID.
-func (cs Customers) RandomSelect() (k int, v *Customer) {
-	mapKeys := reflect.ValueOf(cs).MapKeys()
-	selectedKey := mapKeys[rand.Intn(len(mapKeys))].Interface().(int)
+// IDs returns the customer IDs in ascending order.
+func (cs Customers) IDs() []int {
+	ids := make([]int, 0, len(cs))
+	for id := range cs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// RandomSelect returns a random customer and their ID,
+// drawn from the provided random number generator.
+func (cs Customers) RandomSelect(rng *rand.Rand) (k int, v *Customer) {
+	ids := cs.IDs()
+	selectedKey := ids[rng.Intn(len(ids))]
 	return selectedKey, cs[selectedKey]
 }
 
@@ -3,7 +3,7 @@ package entities
 import (
 	"fmt"
 	"math/rand"
-	"reflect"
+	"sort"
 )
 
 // Depot describes a depot.
@@ -13,6 +13,9 @@ type Depot struct {
 	// Position of the depot.
 	X, Y float64
 
+	// Name of the depot, if any.
+	Name string
+
 	// MaxNumVehicles is how many vehicles/routes
 	// can be dispatched from this depot.
 	MaxNumVehicles int
@@ -26,6 +29,20 @@ type Depot struct {
 	// can take when dispatched from this depot.
 	// This is linked with customer's demands.
 	MaxVehicleLoad float64
+
+	// OpenRoutes is true if the depot's vehicles end their
+	// routes at the last customer instead of returning.
+	OpenRoutes bool
+
+	// OpeningHours is when vehicles may leave and return
+	// to the depot. It is nil if the depot is always open.
+	// Vehicles on open routes only have to leave in time.
+	OpeningHours *TimeWindow
+
+	// Fleet is the depot's mix of vehicle types. It is nil if
+	// all of the depot's vehicles are alike, as described by
+	// MaxNumVehicles, MaxRouteDuration and MaxVehicleLoad.
+	Fleet []VehicleType
 }
 
 // String returns the stringified depot.
@@ -38,6 +55,20 @@ func (d *Depot) String() string {
 )`, d.X, d.Y, d.MaxNumVehicles, d.MaxRouteDuration, d.MaxVehicleLoad)
 }
 
+// VehicleTypes returns the depot's vehicle types. A depot without
+// a fleet has a single type with a distance cost of 1.
+func (d *Depot) VehicleTypes() []VehicleType {
+	if d.Fleet != nil {
+		return d.Fleet
+	}
+	return []VehicleType{{
+		Count:            d.MaxNumVehicles,
+		MaxLoad:          d.MaxVehicleLoad,
+		MaxRouteDuration: d.MaxRouteDuration,
+		DistanceCost:     1,
+	}}
+}
+
 // GetPosition returns the x and y position of the depot.
 func (d *Depot) GetPosition() (X, Y float64) {
 	return d.X, d.Y
@@ -47,10 +78,21 @@ func (d *Depot) GetPosition() (X, Y float64) {
 // The key is the depot's ID.
 type Depots map[int]*Depot
 
-// RandomSelect returns a random depot and its ID.
-func (ds Depots) RandomSelect() (k int, v *Depot) {
-	mapKeys := reflect.ValueOf(ds).MapKeys()
-	selectedKey := mapKeys[rand.Intn(len(mapKeys))].Interface().(int)
+// IDs returns the depot IDs in ascending order.
+func (ds Depots) IDs() []int {
+	ids := make([]int, 0, len(ds))
+	for id := range ds {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// RandomSelect returns a random depot and its ID,
+// drawn from the provided random number generator.
+func (ds Depots) RandomSelect(rng *rand.Rand) (k int, v *Depot) {
+	ids := ds.IDs()
+	selectedKey := ids[rng.Intn(len(ids))]
 	return selectedKey, ds[selectedKey]
 }
 
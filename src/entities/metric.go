@@ -0,0 +1,90 @@
+package entities
+
+import "math"
+
+// Metric measures the distance between two locations.
+type Metric interface {
+	Distance(a, b Location) float64
+}
+
+// Euclidean measures the straight-line distance between locations.
+type Euclidean struct{}
+
+// Distance returns the euclidean distance between a and b.
+func (Euclidean) Distance(a, b Location) float64 {
+	ax, ay := a.GetPosition()
+	bx, by := b.GetPosition()
+	return math.Sqrt(math.Pow(ax-bx, 2) + math.Pow(ay-by, 2))
+}
+
+// RoundedEuclidean measures the euclidean distance
+// rounded to the nearest integer, as in TSPLIB's EUC_2D.
+type RoundedEuclidean struct{}
+
+// Distance returns the rounded euclidean distance between a and b.
+func (RoundedEuclidean) Distance(a, b Location) float64 {
+	return math.Floor(Euclidean{}.Distance(a, b) + 0.5)
+}
+
+// CeiledEuclidean measures the euclidean distance
+// rounded up to the next integer, as in TSPLIB's CEIL_2D.
+type CeiledEuclidean struct{}
+
+// Distance returns the ceiled euclidean distance between a and b.
+func (CeiledEuclidean) Distance(a, b Location) float64 {
+	return math.Ceil(Euclidean{}.Distance(a, b))
+}
+
+// IsSymmetric returns true if the metric measures the same distance
+// from a to b as from b to a. Unknown metrics are assumed not to.
+func IsSymmetric(m Metric) bool {
+	switch m := m.(type) {
+	case Euclidean, RoundedEuclidean, CeiledEuclidean:
+		return true
+	case *DistanceMatrix:
+		for i, row := range m.values {
+			for j := range row {
+				if j >= len(m.values) || i >= len(m.values[j]) || row[j] != m.values[j][i] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// DistanceMatrix is a metric of explicitly given distances.
+// Locations are mapped to rows and columns of the matrix.
+type DistanceMatrix struct {
+	index  map[Location]int
+	values [][]float64
+}
+
+// NewDistanceMatrix creates a distance matrix with the provided
+// values, where values[i][j] is the distance from i to j.
+func NewDistanceMatrix(values [][]float64) *DistanceMatrix {
+	return &DistanceMatrix{
+		index:  make(map[Location]int),
+		values: values,
+	}
+}
+
+// SetIndex maps the location to row and column i of the matrix.
+func (m *DistanceMatrix) SetIndex(l Location, i int) {
+	m.index[l] = i
+}
+
+// Distance returns the distance from a to b.
+// It panics if either location has no index.
+func (m *DistanceMatrix) Distance(a, b Location) float64 {
+	i, ok := m.index[a]
+	if !ok {
+		panic("Location is not in the distance matrix")
+	}
+	j, ok := m.index[b]
+	if !ok {
+		panic("Location is not in the distance matrix")
+	}
+	return m.values[i][j]
+}
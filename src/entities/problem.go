@@ -0,0 +1,37 @@
+package entities
+
+// ProblemType is the type of a vehicle routing problem.
+type ProblemType string
+
+const (
+	VRP     ProblemType = "VRP"
+	PVRP    ProblemType = "PVRP"
+	MDVRP   ProblemType = "MDVRP"
+	SDVRP   ProblemType = "SDVRP"
+	VRPTW   ProblemType = "VRPTW"
+	PVRPTW  ProblemType = "PVRPTW"
+	MDVRPTW ProblemType = "MDVRPTW"
+	SDVRPTW ProblemType = "SDVRPTW"
+)
+
+// IsMultiDepot returns true if the problem type has multiple depots.
+func (pt ProblemType) IsMultiDepot() bool {
+	return pt == MDVRP || pt == MDVRPTW
+}
+
+// IsPeriodic returns true if customers are visited
+// over a planning horizon of several days.
+func (pt ProblemType) IsPeriodic() bool {
+	return pt == PVRP || pt == PVRPTW
+}
+
+// IsSiteDependent returns true if customers can only
+// be served by some vehicle types.
+func (pt ProblemType) IsSiteDependent() bool {
+	return pt == SDVRP || pt == SDVRPTW
+}
+
+// HasTimeWindows returns true if customers have time windows.
+func (pt ProblemType) HasTimeWindows() bool {
+	return pt == VRPTW || pt == PVRPTW || pt == MDVRPTW || pt == SDVRPTW
+}
@@ -0,0 +1,13 @@
+package entities
+
+import "fmt"
+
+// TimeWindow is an interval of time.
+type TimeWindow struct {
+	Start, End float64
+}
+
+// String returns the stringified time window.
+func (tw TimeWindow) String() string {
+	return fmt.Sprintf("[%.2f, %.2f]", tw.Start, tw.End)
+}
@@ -0,0 +1,22 @@
+package entities
+
+// VehicleType describes a type of vehicle in a depot's fleet.
+type VehicleType struct {
+	// Name of the vehicle type, if any.
+	Name string
+
+	// Count is how many vehicles of the type the depot has.
+	Count int
+
+	// MaxLoad is how much load a vehicle of the type can take.
+	MaxLoad float64
+
+	// MaxRouteDuration is how long a route driven by a vehicle
+	// of the type can take. 0 means that there is no limit.
+	MaxRouteDuration float64
+
+	// FixedCost is the cost of using a vehicle of the type
+	// and DistanceCost is its cost per distance traveled.
+	FixedCost    float64
+	DistanceCost float64
+}
@@ -1,52 +1,198 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/problem"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/render"
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/visualizer"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/web"
+)
+
+var (
+	metricsPath = flag.String("metrics", "", "write per-generation metrics to this file (.csv or .jsonl)")
+	geoJSONPath = flag.String("geojson", "", "write the best solution to this GeoJSON file when finished")
+	lonLat      = flag.Bool("lonlat", false, "interpret coordinates as longitude and latitude in GeoJSON")
+	headless    = flag.Bool("headless", false, "run without opening a window")
+	imagePath   = flag.String("image", "", "render the best solution to this PNG or SVG file when finished (%d is replaced by the generation)")
+	imageEvery  = flag.Int("image-every", 0, "also render the image every n generations")
+	gifPath     = flag.String("gif", "", "write an animated GIF replay of the best solutions to this file when finished")
+	gifEvery    = flag.Int("gif-every", 10, "record a GIF frame every n generations, less often in long runs")
+	openRoutes  = flag.Bool("open", false, "make all routes open, ending at the last customer instead of the depot")
+	quiet       = flag.Bool("quiet", false, "only log generations where the best solution improved")
+	comparePath = flag.String("compare", "", "compare the best solution with this solution (.res) in the window")
+	httpAddr    = flag.String("http", "", "serve a live visualizer on this address (e.g. :8080) instead of opening a window")
 )
 
 func main() {
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *headless || *httpAddr != "" {
+		solveProblem(ctx, "problems/p23", nil)
+		return
+	}
+
 	gui, err := visualizer.New()
 	if err != nil {
 		panic(err)
 	}
 
-	go solveProblem("problems/p23", gui)
+	go func() {
+		solveProblem(ctx, "problems/p23", gui)
+		gui.Stop()
+	}()
 
 	gui.Run()
 }
 
-func solveProblem(path string, gui *visualizer.Instance) {
-	depots, customers, err := LoadProblem(path)
+func solveProblem(ctx context.Context, path string, gui *visualizer.Instance) {
+	instance, err := problem.LoadInstance(path)
 	if err != nil {
 		panic(err)
 	}
+	depots, customers := instance.Depots, instance.Customers
+	if *openRoutes {
+		for _, depot := range depots {
+			depot.OpenRoutes = true
+		}
+	}
 
-	slvr, err := solver.NewSolver(solver.SolverConfig{
-		Depots:    depots,
-		Customers: customers,
-
-		PopulationSize:  128,
-		SelectionSize:   0.5,
-		SelectionMethod: solver.Roulette,
-
-		// 1/n chances:
-		RandomChanceRouteSplit:              20,
-		RandomChanceDepotRelocation:         50,
-		RandomChanceEvaluateOuterDepotRoute: 100000,
-	})
+	cfg := solver.DefaultConfig()
+	cfg.Depots = depots
+	cfg.Customers = customers
+	cfg.Instance = problem.InstanceName(path)
+	cfg.ProblemType = instance.Type
+	cfg.Metric = instance.Metric
+	slvr, err := solver.NewSolver(cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	slvr.PostIterationCallback = func(info solver.GenerationInfo) {
-		fmt.Printf("%s (generation %d)\n", path, info.GenerationNumber)
-		fmt.Printf("\tBest error:  %v\n", info.BestAgent.Fitness)
-		fmt.Printf("\tTotal error: %v\n\n", info.PopulationFitness)
-		gui.Draw(depots, customers, info.BestAgent)
+	logger := solver.NewLogger(os.Stdout, path)
+	logger.ImprovementsOnly = *quiet
+	slvr.Subscribe(logger)
+
+	if gui != nil {
+		slvr.Subscribe(gui)
+
+		if *comparePath != "" {
+			dna, err := solver.LoadSolution(*comparePath)
+			if err != nil {
+				panic(err)
+			}
+			if err := dna.Validate(depots, customers); err != nil {
+				panic(err)
+			}
+			reference := &solver.Agent{Dna: dna}
+			reference.Evaluate(slvr)
+			gui.SetReference(reference, filepath.Base(*comparePath))
+		}
+	}
+
+	if *metricsPath != "" {
+		file, err := os.Create(*metricsPath)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+
+		format := solver.CSV
+		if filepath.Ext(*metricsPath) == ".jsonl" {
+			format = solver.JSONLines
+		}
+		metrics, err := solver.NewMetricsRecorder(file, format)
+		if err != nil {
+			panic(err)
+		}
+		slvr.Subscribe(metrics)
+		defer func() {
+			if err := metrics.Err(); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	var replay *render.Replay
+	if *gifPath != "" {
+		replay = render.NewReplay(*gifEvery)
+		slvr.Subscribe(replay)
+	}
+
+	var live *web.Server
+	if *httpAddr != "" {
+		if live, err = web.NewServer(depots, customers); err != nil {
+			panic(err)
+		}
+		slvr.Subscribe(live)
+		server := &http.Server{Addr: *httpAddr, Handler: live}
+		go func() {
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				panic(err)
+			}
+		}()
+		defer server.Close()
+		fmt.Printf("Serving the visualizer on %s\n", *httpAddr)
+	}
+
+	if *imagePath != "" && *imageEvery > 0 {
+		slvr.Subscribe(solver.ObserverFunc(func(info solver.GenerationInfo) {
+			if info.GenerationNumber%*imageEvery == 0 {
+				renderImage(*imagePath, depots, customers, info)
+			}
+		}))
 	}
 
-	_ = slvr.Solve(solver.EndCondition{})
+	result := slvr.Solve(ctx, solver.EndCondition{})
+
+	if *imagePath != "" {
+		renderImage(*imagePath, depots, customers, result)
+	}
+
+	if replay != nil {
+		if err := replay.WriteGIFFile(*gifPath, 1280, 720, 100*time.Millisecond, depots, customers); err != nil {
+			panic(err)
+		}
+	}
+
+	if *geoJSONPath != "" {
+		file, err := os.Create(*geoJSONPath)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+
+		if err := slvr.WriteGeoJSON(file, result.BestAgent, solver.GeoJSONOptions{LonLat: *lonLat}); err != nil {
+			panic(err)
+		}
+	}
+
+	if live != nil && ctx.Err() == nil {
+		// Keep showing the result until interrupted.
+		fmt.Println("Press Ctrl+C to stop the visualizer")
+		<-ctx.Done()
+	}
+}
+
+// renderImage renders the generation's best agent to an image file.
+// A %d in the path is replaced by the generation number.
+func renderImage(path string, depots entities.Depots, customers entities.Customers, info solver.GenerationInfo) {
+	if strings.Contains(path, "%d") {
+		path = fmt.Sprintf(path, info.GenerationNumber)
+	}
+	if err := render.WriteFile(path, 1280, 720, depots, customers, info.BestAgent); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,50 @@
+package problem
+
+import (
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed benchmarks.txt
+var benchmarksFile string
+
+// BestKnown contains the best-known costs of the Cordeau
+// instances in problems/. The key is the instance name.
+var BestKnown = parseBenchmarks(benchmarksFile)
+
+// parseBenchmarks parses lines of instance names and costs.
+// Empty lines and lines starting with # are ignored.
+func parseBenchmarks(text string) map[string]float64 {
+	benchmarks := map[string]float64{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, cost := "", 0.0
+		if _, err := fmt.Sscanf(line, "%s %f", &name, &cost); err != nil {
+			panic(fmt.Errorf("Invalid benchmark %q: %v", line, err))
+		}
+		benchmarks[name] = cost
+	}
+	return benchmarks
+}
+
+// InstanceName returns the instance name of a problem file,
+// e.g. "p01" for "problems/p01".
+func InstanceName(filePath string) string {
+	return strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+}
+
+// Gap returns the gap (%) between the cost and the best-known
+// cost of the instance. ok is false for unknown instances.
+func Gap(instance string, cost float64) (gap float64, ok bool) {
+	bks, ok := BestKnown[instance]
+	if !ok {
+		return 0, false
+	}
+	return 100 * (cost - bks) / bks, true
+}
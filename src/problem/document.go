@@ -0,0 +1,503 @@
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// Document is the JSON/YAML problem schema. Unlike the Cordeau
+// format, every depot has its own fleet and constraints, and
+// depots and customers can be named.
+type Document struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// OpenRoutes makes the routes of all depots open.
+	OpenRoutes bool `json:"open_routes,omitempty" yaml:"open_routes,omitempty"`
+
+	// Metric measures distances. It is one of "euclidean" (the
+	// default), "rounded_euclidean", "ceiled_euclidean" and
+	// "explicit". Explicit distances are given by Distances,
+	// where Distances[i][j] is the distance from location i to
+	// j, and locations are the depots followed by the customers
+	// in the order they are listed. Coordinates are then optional.
+	Metric    string      `json:"metric,omitempty" yaml:"metric,omitempty"`
+	Distances [][]float64 `json:"distances,omitempty" yaml:"distances,omitempty"`
+
+	Depots    []DocumentDepot    `json:"depots" yaml:"depots"`
+	Customers []DocumentCustomer `json:"customers" yaml:"customers"`
+}
+
+// DocumentDepot describes a depot and its fleet.
+// Depots are given IDs from 0 in the order they are listed.
+type DocumentDepot struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// X and Y are the depot's coordinates, which are
+	// required unless distances are explicit.
+	X *float64 `json:"x" yaml:"x"`
+	Y *float64 `json:"y" yaml:"y"`
+
+	// Vehicles is the number of vehicles in the depot's fleet.
+	Vehicles int `json:"vehicles,omitempty" yaml:"vehicles,omitempty"`
+
+	// Capacity is the maximum load of each vehicle.
+	Capacity float64 `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+
+	// MaxDuration is the maximum duration of a route.
+	// 0 means that there is no limit.
+	MaxDuration float64 `json:"max_duration,omitempty" yaml:"max_duration,omitempty"`
+
+	// OpenRoutes is true if vehicles end their routes
+	// at the last customer instead of returning.
+	OpenRoutes bool `json:"open_routes,omitempty" yaml:"open_routes,omitempty"`
+
+	// OpeningHours is when vehicles may leave and return.
+	// The depot is always open if it is left out.
+	OpeningHours *DocumentTimeWindow `json:"opening_hours,omitempty" yaml:"opening_hours,omitempty"`
+
+	// Fleet is the depot's mix of vehicle types. It is used
+	// instead of vehicles, capacity and max duration.
+	Fleet []DocumentVehicleType `json:"fleet,omitempty" yaml:"fleet,omitempty"`
+}
+
+// DocumentVehicleType describes a type of vehicle in a depot's fleet.
+type DocumentVehicleType struct {
+	Name        string  `json:"name,omitempty" yaml:"name,omitempty"`
+	Count       int     `json:"count" yaml:"count"`
+	Capacity    float64 `json:"capacity" yaml:"capacity"`
+	MaxDuration float64 `json:"max_duration,omitempty" yaml:"max_duration,omitempty"`
+	FixedCost   float64 `json:"fixed_cost,omitempty" yaml:"fixed_cost,omitempty"`
+
+	// DistanceCost is the cost per distance traveled.
+	// It is 1 if it is left out.
+	DistanceCost *float64 `json:"distance_cost,omitempty" yaml:"distance_cost,omitempty"`
+}
+
+// DocumentCustomer describes a customer.
+type DocumentCustomer struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// X and Y are the customer's coordinates, which are
+	// required unless distances are explicit.
+	X *float64 `json:"x" yaml:"x"`
+	Y *float64 `json:"y" yaml:"y"`
+
+	Demand      float64             `json:"demand" yaml:"demand"`
+	ServiceTime float64             `json:"service_time,omitempty" yaml:"service_time,omitempty"`
+	TimeWindow  *DocumentTimeWindow `json:"time_window,omitempty" yaml:"time_window,omitempty"`
+
+	// Frequency and Combinations are the visit frequency and
+	// allowed visit combinations of the Cordeau format.
+	Frequency    int   `json:"frequency,omitempty" yaml:"frequency,omitempty"`
+	Combinations []int `json:"combinations,omitempty" yaml:"combinations,omitempty"`
+}
+
+// DocumentTimeWindow is when service may start
+// or when a depot is open.
+type DocumentTimeWindow struct {
+	Start float64 `json:"start" yaml:"start"`
+	End   float64 `json:"end" yaml:"end"`
+}
+
+// isDocument returns true if the file extension
+// is one of a JSON or YAML document.
+func isDocument(filePath string) bool {
+	switch filepath.Ext(filePath) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// LoadDocument reads a problem instance from a JSON or
+// YAML document in the specified filePath. The format is
+// chosen from the file extension.
+func LoadDocument(filePath string) (*Instance, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if filepath.Ext(filePath) == ".json" {
+		return ParseJSON(file, filePath)
+	}
+	return ParseYAML(file, filePath)
+}
+
+// ParseJSON parses a problem instance from a JSON document.
+// The name is used to give context to errors, e.g. the file name.
+func ParseJSON(r io.Reader, name string) (*Instance, error) {
+	doc := &Document{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(doc); err != nil {
+		return nil, &ParseError{File: name, Err: err}
+	}
+
+	return doc.Instance(name)
+}
+
+// ParseYAML parses a problem instance from a YAML document.
+// The name is used to give context to errors, e.g. the file name.
+func ParseYAML(r io.Reader, name string) (*Instance, error) {
+	doc := &Document{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(doc); err != nil {
+		return nil, &ParseError{File: name, Err: err}
+	}
+
+	return doc.Instance(name)
+}
+
+// Instance validates the document and converts it to an instance.
+// The name is used to give context to errors, e.g. the file name.
+func (doc *Document) Instance(name string) (*Instance, error) {
+	errorf := func(format string, args ...interface{}) error {
+		return &ParseError{File: name, Err: fmt.Errorf(format, args...)}
+	}
+
+	if len(doc.Depots) == 0 {
+		return nil, errorf("No depots")
+	}
+	if len(doc.Customers) == 0 {
+		return nil, errorf("No customers")
+	}
+
+	instance := &Instance{
+		Type:      entities.VRP,
+		Depots:    make(entities.Depots),
+		Customers: make(entities.Customers),
+		Metric:    entities.Euclidean{},
+	}
+	if len(doc.Depots) > 1 {
+		instance.Type = entities.MDVRP
+	}
+	explicit := doc.Metric == "explicit"
+	if doc.Distances != nil && !explicit {
+		return nil, errorf("Distances are only given with the explicit metric")
+	}
+
+	// locations are the depots and customers in the order
+	// they are listed, which index explicit distances.
+	locations := []entities.Location{}
+
+	for i, d := range doc.Depots {
+		if (d.X == nil || d.Y == nil) && !explicit {
+			return nil, errorf("Depot %d: missing coordinates", i)
+		}
+		if d.Fleet != nil {
+			if d.Vehicles != 0 || d.Capacity != 0 || d.MaxDuration != 0 {
+				return nil, errorf("Depot %d: vehicles, capacity and max duration are given by the fleet", i)
+			}
+			if err := d.setFleetLimits(); err != nil {
+				return nil, errorf("Depot %d: %v", i, err)
+			}
+		}
+		if d.Vehicles <= 0 {
+			return nil, errorf("Depot %d: vehicles must be positive", i)
+		}
+		if d.Capacity <= 0 {
+			return nil, errorf("Depot %d: capacity must be positive", i)
+		}
+		if d.MaxDuration < 0 {
+			return nil, errorf("Depot %d: max duration cannot be negative", i)
+		}
+
+		depot := &entities.Depot{
+			X:                coordinateValue(d.X),
+			Y:                coordinateValue(d.Y),
+			Name:             d.Name,
+			MaxNumVehicles:   d.Vehicles,
+			MaxRouteDuration: d.MaxDuration,
+			MaxVehicleLoad:   d.Capacity,
+			OpenRoutes:       doc.OpenRoutes || d.OpenRoutes,
+		}
+		if d.OpeningHours != nil {
+			if d.OpeningHours.Start > d.OpeningHours.End {
+				return nil, errorf("Depot %d: opening hours start after they end", i)
+			}
+			depot.OpeningHours = &entities.TimeWindow{
+				Start: d.OpeningHours.Start,
+				End:   d.OpeningHours.End,
+			}
+		}
+		for _, v := range d.Fleet {
+			vehicle := entities.VehicleType{
+				Name:             v.Name,
+				Count:            v.Count,
+				MaxLoad:          v.Capacity,
+				MaxRouteDuration: v.MaxDuration,
+				FixedCost:        v.FixedCost,
+				DistanceCost:     1,
+			}
+			if v.DistanceCost != nil {
+				vehicle.DistanceCost = *v.DistanceCost
+			}
+			depot.Fleet = append(depot.Fleet, vehicle)
+		}
+		instance.Depots[i] = depot
+		locations = append(locations, depot)
+		instance.Constraints = append(instance.Constraints, Constraint{
+			MaxRouteDuration: d.MaxDuration,
+			MaxVehicleLoad:   d.Capacity,
+		})
+		if d.Vehicles > instance.MaxNumVehicles {
+			instance.MaxNumVehicles = d.Vehicles
+		}
+	}
+
+	for _, c := range doc.Customers {
+		if c.ID <= 0 {
+			return nil, errorf("Customer %d: ID must be positive", c.ID)
+		}
+		if _, ok := instance.Customers[c.ID]; ok {
+			return nil, errorf("Duplicate customer ID %d", c.ID)
+		}
+		if (c.X == nil || c.Y == nil) && !explicit {
+			return nil, errorf("Customer %d: missing coordinates", c.ID)
+		}
+		if c.Demand < 0 || c.ServiceTime < 0 {
+			return nil, errorf("Customer %d: demand and service time cannot be negative", c.ID)
+		}
+
+		customer := &entities.Customer{
+			ID:              c.ID,
+			Name:            c.Name,
+			X:               coordinateValue(c.X),
+			Y:               coordinateValue(c.Y),
+			ServiceDuration: c.ServiceTime,
+			Demand:          c.Demand,
+			Frequency:       c.Frequency,
+			Combinations:    c.Combinations,
+		}
+		if c.TimeWindow != nil {
+			if c.TimeWindow.Start > c.TimeWindow.End {
+				return nil, errorf("Customer %d: time window starts after it ends", c.ID)
+			}
+			customer.TimeWindow = &entities.TimeWindow{
+				Start: c.TimeWindow.Start,
+				End:   c.TimeWindow.End,
+			}
+			if instance.Type == entities.MDVRP {
+				instance.Type = entities.MDVRPTW
+			} else if instance.Type == entities.VRP {
+				instance.Type = entities.VRPTW
+			}
+		}
+		instance.Customers[c.ID] = customer
+		locations = append(locations, customer)
+	}
+
+	metric, err := doc.metric(locations)
+	if err != nil {
+		return nil, errorf("%v", err)
+	}
+	instance.Metric = metric
+
+	return instance, nil
+}
+
+// metric returns the document's metric. Explicit distances
+// are indexed by the locations.
+func (doc *Document) metric(locations []entities.Location) (entities.Metric, error) {
+	switch doc.Metric {
+	case "", "euclidean":
+		return entities.Euclidean{}, nil
+	case "rounded_euclidean":
+		return entities.RoundedEuclidean{}, nil
+	case "ceiled_euclidean":
+		return entities.CeiledEuclidean{}, nil
+	case "explicit":
+	default:
+		return nil, fmt.Errorf("Unknown metric %q", doc.Metric)
+	}
+
+	if len(doc.Distances) != len(locations) {
+		return nil, fmt.Errorf("Expected %d rows of distances, got %d", len(locations), len(doc.Distances))
+	}
+	matrix := entities.NewDistanceMatrix(doc.Distances)
+	for i, row := range doc.Distances {
+		if len(row) != len(locations) {
+			return nil, fmt.Errorf("Expected %d distances in row %d, got %d", len(locations), i, len(row))
+		}
+		matrix.SetIndex(locations[i], i)
+	}
+	return matrix, nil
+}
+
+// setFleetLimits validates the depot's fleet and sets the number of
+// vehicles, capacity and max duration to the fleet's total number of
+// vehicles and largest capacity and max duration.
+func (d *DocumentDepot) setFleetLimits() error {
+	for j, v := range d.Fleet {
+		if v.Count <= 0 {
+			return fmt.Errorf("vehicle type %d: count must be positive", j)
+		}
+		if v.Capacity <= 0 {
+			return fmt.Errorf("vehicle type %d: capacity must be positive", j)
+		}
+		if v.MaxDuration < 0 || v.FixedCost < 0 || (v.DistanceCost != nil && *v.DistanceCost < 0) {
+			return fmt.Errorf("vehicle type %d: max duration and costs cannot be negative", j)
+		}
+
+		d.Vehicles += v.Count
+		d.Capacity = math.Max(d.Capacity, v.Capacity)
+		if v.MaxDuration == 0 || (j > 0 && d.MaxDuration == 0) {
+			// A vehicle type without a limit lifts the depot's limit.
+			d.MaxDuration = 0
+		} else {
+			d.MaxDuration = math.Max(d.MaxDuration, v.MaxDuration)
+		}
+	}
+	if d.Vehicles == 0 {
+		return fmt.Errorf("fleet has no vehicle types")
+	}
+	return nil
+}
+
+// NewDocument converts an instance to a document.
+// Depots are listed in ID order and customers by ID.
+// Periodic and site-dependent instances and custom
+// metrics cannot be represented as documents.
+func NewDocument(name string, instance *Instance) (*Document, error) {
+	switch instance.Type {
+	case entities.VRP, entities.MDVRP, entities.VRPTW, entities.MDVRPTW:
+	default:
+		return nil, fmt.Errorf("%s instances cannot be represented as documents", instance.Type)
+	}
+
+	doc := &Document{Name: name}
+	locations := []entities.Location{}
+
+	depotIDs := []int{}
+	for id := range instance.Depots {
+		depotIDs = append(depotIDs, id)
+	}
+	sort.Ints(depotIDs)
+	for _, id := range depotIDs {
+		d := instance.Depots[id]
+		depot := DocumentDepot{
+			Name:        d.Name,
+			X:           coordinate(d.X),
+			Y:           coordinate(d.Y),
+			Vehicles:    d.MaxNumVehicles,
+			Capacity:    d.MaxVehicleLoad,
+			MaxDuration: d.MaxRouteDuration,
+			OpenRoutes:  d.OpenRoutes,
+		}
+		if d.Fleet != nil {
+			depot.Vehicles, depot.Capacity, depot.MaxDuration = 0, 0, 0
+			for _, v := range d.Fleet {
+				distanceCost := v.DistanceCost
+				depot.Fleet = append(depot.Fleet, DocumentVehicleType{
+					Name:         v.Name,
+					Count:        v.Count,
+					Capacity:     v.MaxLoad,
+					MaxDuration:  v.MaxRouteDuration,
+					FixedCost:    v.FixedCost,
+					DistanceCost: &distanceCost,
+				})
+			}
+		}
+		if d.OpeningHours != nil {
+			depot.OpeningHours = &DocumentTimeWindow{
+				Start: d.OpeningHours.Start,
+				End:   d.OpeningHours.End,
+			}
+		}
+		doc.Depots = append(doc.Depots, depot)
+		locations = append(locations, d)
+	}
+
+	customerIDs := []int{}
+	for id := range instance.Customers {
+		customerIDs = append(customerIDs, id)
+	}
+	sort.Ints(customerIDs)
+	for _, id := range customerIDs {
+		c := instance.Customers[id]
+		customer := DocumentCustomer{
+			ID:          c.ID,
+			Name:        c.Name,
+			X:           coordinate(c.X),
+			Y:           coordinate(c.Y),
+			Demand:      c.Demand,
+			ServiceTime: c.ServiceDuration,
+			Frequency:   c.Frequency,
+		}
+		if c.Combinations != nil {
+			customer.Combinations = append([]int{}, c.Combinations...)
+		}
+		if c.TimeWindow != nil {
+			customer.TimeWindow = &DocumentTimeWindow{
+				Start: c.TimeWindow.Start,
+				End:   c.TimeWindow.End,
+			}
+		}
+		doc.Customers = append(doc.Customers, customer)
+		locations = append(locations, c)
+	}
+
+	switch instance.Metric.(type) {
+	case nil, entities.Euclidean:
+	case entities.RoundedEuclidean:
+		doc.Metric = "rounded_euclidean"
+	case entities.CeiledEuclidean:
+		doc.Metric = "ceiled_euclidean"
+	case *entities.DistanceMatrix:
+		doc.Metric = "explicit"
+		for _, a := range locations {
+			row := make([]float64, len(locations))
+			for j, b := range locations {
+				row[j] = instance.Metric.Distance(a, b)
+			}
+			doc.Distances = append(doc.Distances, row)
+		}
+	default:
+		return nil, fmt.Errorf("Metric %T cannot be represented in documents", instance.Metric)
+	}
+
+	return doc, nil
+}
+
+// coordinate returns a pointer to a copy of the coordinate.
+func coordinate(v float64) *float64 {
+	return &v
+}
+
+// coordinateValue returns the coordinate, or 0 if it is left out.
+func coordinateValue(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// WriteJSON writes the document as indented JSON.
+func (doc *Document) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteYAML writes the document as YAML.
+func (doc *Document) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,236 @@
+package problem
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestParseJSON(t *testing.T) {
+	text := `{
+  "name": "test",
+  "depots": [
+    {"name": "north", "x": 0, "y": 10, "vehicles": 2, "capacity": 50, "max_duration": 200},
+    {"x": 5, "y": 0, "vehicles": 1, "capacity": 80, "open_routes": true}
+  ],
+  "customers": [
+    {"id": 1, "name": "a", "x": 1, "y": 2, "demand": 10, "service_time": 3},
+    {"id": 7, "x": 3, "y": 4, "demand": 20, "time_window": {"start": 10, "end": 20}}
+  ]
+}`
+	instance, err := ParseJSON(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Type != entities.MDVRPTW || instance.MaxNumVehicles != 2 {
+		t.Errorf("Expected an MDVRPTW with 2 vehicles, got %v with %d", instance.Type, instance.MaxNumVehicles)
+	}
+	north := instance.Depots[0]
+	if north.Name != "north" || north.Y != 10 || north.MaxNumVehicles != 2 || north.MaxVehicleLoad != 50 || north.MaxRouteDuration != 200 || north.OpenRoutes {
+		t.Errorf("Unexpected depot %v", north)
+	}
+	if !instance.Depots[1].OpenRoutes {
+		t.Error("Expected the second depot's routes to be open")
+	}
+	if c := instance.Customers[1]; c.Name != "a" || c.ServiceDuration != 3 || c.Demand != 10 || c.TimeWindow != nil {
+		t.Errorf("Unexpected customer %+v", c)
+	}
+	if tw := instance.Customers[7].TimeWindow; tw == nil || *tw != (entities.TimeWindow{Start: 10, End: 20}) {
+		t.Errorf("Expected time window 10-20, got %v", tw)
+	}
+}
+
+func TestParseYAMLFleet(t *testing.T) {
+	text := `
+open_routes: true
+depots:
+  - x: 0
+    y: 0
+    opening_hours: {start: 0, end: 100}
+    fleet:
+      - {name: van, count: 2, capacity: 20, max_duration: 50}
+      - {name: truck, count: 1, capacity: 60, max_duration: 80, fixed_cost: 10, distance_cost: 2}
+customers:
+  - {id: 1, x: 1, y: 1, demand: 5}
+`
+	instance, err := ParseYAML(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depot := instance.Depots[0]
+	if !depot.OpenRoutes {
+		t.Error("Expected open routes")
+	}
+	if depot.MaxNumVehicles != 3 || depot.MaxVehicleLoad != 60 || depot.MaxRouteDuration != 80 {
+		t.Errorf("Expected the fleet's limits 3, 60 and 80, got %v", depot)
+	}
+	if depot.OpeningHours == nil || *depot.OpeningHours != (entities.TimeWindow{Start: 0, End: 100}) {
+		t.Errorf("Expected opening hours 0-100, got %v", depot.OpeningHours)
+	}
+	want := []entities.VehicleType{
+		{Name: "van", Count: 2, MaxLoad: 20, MaxRouteDuration: 50, DistanceCost: 1},
+		{Name: "truck", Count: 1, MaxLoad: 60, MaxRouteDuration: 80, FixedCost: 10, DistanceCost: 2},
+	}
+	if !reflect.DeepEqual(depot.Fleet, want) {
+		t.Errorf("Expected fleet %v, got %v", want, depot.Fleet)
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	customers := `"customers": [{"id": 1, "x": 1, "y": 1, "demand": 1}]`
+	depots := `"depots": [{"x": 0, "y": 0, "vehicles": 1, "capacity": 10}]`
+
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"no depots", `{"depots": [], ` + customers + `}`, "No depots"},
+		{"depot without coordinates", `{"depots": [{"x": 0, "vehicles": 1, "capacity": 10}], ` + customers + `}`, "Depot 0: missing coordinates"},
+		{"customer without coordinates", `{` + depots + `, "customers": [{"id": 3, "y": 1, "demand": 1}]}`, "Customer 3: missing coordinates"},
+		{"no customers", `{` + depots + `, "customers": []}`, "No customers"},
+		{"unknown field", `{"vehicles": 1, ` + depots + `, ` + customers + `}`, "unknown field"},
+		{"no vehicles", `{"depots": [{"x": 0, "y": 0, "capacity": 10}], ` + customers + `}`, "Depot 0: vehicles must be positive"},
+		{"no capacity", `{"depots": [{"x": 0, "y": 0, "vehicles": 1}], ` + customers + `}`, "Depot 0: capacity must be positive"},
+		{"fleet and vehicles", `{"depots": [{"x": 0, "y": 0, "vehicles": 1, "fleet": [{"count": 1, "capacity": 1}]}], ` + customers + `}`, "given by the fleet"},
+		{"empty vehicle type", `{"depots": [{"x": 0, "y": 0, "fleet": [{"count": 0, "capacity": 1}]}], ` + customers + `}`, "vehicle type 0: count must be positive"},
+		{"reversed opening hours", `{"depots": [{"x": 0, "y": 0, "vehicles": 1, "capacity": 10, "opening_hours": {"start": 2, "end": 1}}], ` + customers + `}`, "opening hours start after they end"},
+		{"customer ID", `{` + depots + `, "customers": [{"id": 0, "x": 1, "y": 1, "demand": 1}]}`, "ID must be positive"},
+		{"duplicate customer", `{` + depots + `, "customers": [{"id": 1, "x": 1, "y": 1, "demand": 1}, {"id": 1, "x": 1, "y": 1, "demand": 1}]}`, "Duplicate customer ID 1"},
+		{"negative demand", `{` + depots + `, "customers": [{"id": 1, "x": 1, "y": 1, "demand": -1}]}`, "cannot be negative"},
+		{"reversed time window", `{` + depots + `, "customers": [{"id": 1, "x": 1, "y": 1, "demand": 1, "time_window": {"start": 2, "end": 1}}]}`, "time window starts after it ends"},
+		{"unknown metric", `{"metric": "manhattan", ` + depots + `, ` + customers + `}`, "Unknown metric"},
+		{"distances without explicit metric", `{"distances": [[0, 1], [1, 0]], ` + depots + `, ` + customers + `}`, "only given with the explicit metric"},
+		{"missing distances", `{"metric": "explicit", "distances": [[0, 1]], ` + depots + `, ` + customers + `}`, "Expected 2 rows of distances, got 1"},
+		{"short distance row", `{"metric": "explicit", "distances": [[0, 1], [1]], ` + depots + `, ` + customers + `}`, "Expected 2 distances in row 1, got 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseJSON(strings.NewReader(test.text), "test")
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	instance, err := LoadInstance("../../problems/p01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance.Depots[0].OpenRoutes = true
+	instance.Depots[1].Fleet = []entities.VehicleType{{Name: "van", Count: 3, MaxLoad: 80, DistanceCost: 1.5}}
+	instance.Customers[1].TimeWindow = &entities.TimeWindow{Start: 5, End: 15}
+
+	formats := []struct {
+		name  string
+		write func(doc *Document, buf *bytes.Buffer) error
+		parse func(buf *bytes.Buffer) (*Instance, error)
+	}{
+		{"json",
+			func(doc *Document, buf *bytes.Buffer) error { return doc.WriteJSON(buf) },
+			func(buf *bytes.Buffer) (*Instance, error) { return ParseJSON(buf, "test") }},
+		{"yaml",
+			func(doc *Document, buf *bytes.Buffer) error { return doc.WriteYAML(buf) },
+			func(buf *bytes.Buffer) (*Instance, error) { return ParseYAML(buf, "test") }},
+	}
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			doc, err := NewDocument("p01", instance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := format.write(doc, &buf); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := format.parse(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The fleet's limits are derived from the fleet.
+			want := *instance.Depots[1]
+			want.MaxNumVehicles, want.MaxVehicleLoad, want.MaxRouteDuration = 3, 80, 0
+			if !reflect.DeepEqual(*loaded.Depots[1], want) {
+				t.Errorf("Expected depot %+v, got %+v", want, *loaded.Depots[1])
+			}
+			if !reflect.DeepEqual(loaded.Depots[0], instance.Depots[0]) {
+				t.Errorf("Expected depot %+v, got %+v", instance.Depots[0], loaded.Depots[0])
+			}
+			if !reflect.DeepEqual(loaded.Customers, instance.Customers) {
+				t.Error("Expected the customers to round-trip")
+			}
+			if loaded.Metric != instance.Metric {
+				t.Errorf("Expected metric %T, got %T", instance.Metric, loaded.Metric)
+			}
+		})
+	}
+}
+
+func TestDocumentExplicitDistances(t *testing.T) {
+	text := `{
+  "metric": "explicit",
+  "distances": [[0, 4, 6], [5, 0, 2], [7, 3, 0]],
+  "depots": [{"vehicles": 1, "capacity": 10}],
+  "customers": [{"id": 2, "demand": 1}, {"id": 1, "demand": 1}]
+}`
+	instance, err := ParseJSON(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	depot, c1, c2 := instance.Depots[0], instance.Customers[1], instance.Customers[2]
+	if d := instance.Metric.Distance(depot, c2); d != 4 {
+		t.Errorf("Expected 4 from the depot to the first listed customer, got %v", d)
+	}
+	if d := instance.Metric.Distance(c1, c2); d != 3 {
+		t.Errorf("Expected 3 from the second to the first listed customer, got %v", d)
+	}
+
+	// Written documents list customers by ID.
+	doc, err := NewDocument("test", instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{0, 6, 4}, {7, 0, 3}, {5, 2, 0}}
+	if doc.Metric != "explicit" || !reflect.DeepEqual(doc.Distances, want) {
+		t.Errorf("Expected explicit distances %v, got %q %v", want, doc.Metric, doc.Distances)
+	}
+}
+
+func TestNewDocumentUnrepresentable(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *Instance
+		err      string
+	}{
+		{"periodic", &Instance{Type: entities.PVRP}, "PVRP instances cannot be represented"},
+		{"site-dependent", &Instance{Type: entities.SDVRP}, "SDVRP instances cannot be represented"},
+		{"custom metric", &Instance{Type: entities.VRP, Metric: manhattan{}}, "Metric problem.manhattan cannot be represented"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewDocument("test", test.instance)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+// manhattan is a metric documents cannot represent.
+type manhattan struct{}
+
+func (manhattan) Distance(a, b entities.Location) float64 {
+	return 0
+}
@@ -0,0 +1,420 @@
+package problem
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// ParseError is an error at a specific line of a problem file.
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+// Error returns the error prefixed with its file and line.
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Instance is a problem instance in the Cordeau format.
+type Instance struct {
+	Type entities.ProblemType
+
+	// MaxNumVehicles is the number of vehicles available
+	// in each depot, per day or per vehicle type.
+	MaxNumVehicles int
+
+	// Constraints are the route constraints of each depot
+	// (MDVRP), day (PVRP) or vehicle type (SDVRP).
+	Constraints []Constraint
+
+	Depots    entities.Depots
+	Customers entities.Customers
+
+	// Metric measures distances in the instance.
+	Metric entities.Metric
+}
+
+// Constraint describes the limits of a route.
+type Constraint struct {
+	MaxRouteDuration float64
+	MaxVehicleLoad   float64
+}
+
+// problemTypes maps the problem type codes of
+// the Cordeau format to problem types.
+var problemTypes = map[int]entities.ProblemType{
+	0: entities.VRP,
+	1: entities.PVRP,
+	2: entities.MDVRP,
+	3: entities.SDVRP,
+	4: entities.VRPTW,
+	5: entities.PVRPTW,
+	6: entities.MDVRPTW,
+	7: entities.SDVRPTW,
+}
+
+// Load reads and loads depots and customers related
+// to a problem found in a file in the specified filePath.
+// The problem type is not returned; use LoadInstance
+// for problems that may not be MDVRPs.
+func Load(filePath string) (depots entities.Depots, customers entities.Customers, err error) {
+	instance, err := LoadInstance(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance.Depots, instance.Customers, nil
+}
+
+// LoadInstance reads a problem instance from a file in the
+// specified filePath. Files with the .vrp extension are read
+// in the TSPLIB/CVRPLIB format, .json, .yaml and .yml files as
+// documents and others in the Cordeau format.
+func LoadInstance(filePath string) (*Instance, error) {
+	if filepath.Ext(filePath) == ".vrp" {
+		return LoadVRP(filePath)
+	}
+	if isDocument(filePath) {
+		return LoadDocument(filePath)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file, filePath)
+}
+
+// Parse parses a problem in the Cordeau format. The name
+// is used to give context to errors, e.g. the file name.
+//
+// The first line holds the problem type code, the maximum number
+// of vehicles, the number of customers and the number of depots,
+// days or vehicle types (type m n t). The type code may be left
+// out, in which case the problem is an MDVRP.
+// It is followed by t lines of route constraints (D Q) and the
+// vertex lines (i x y d q f a list [e l]). Multi-depot problems
+// list n customers followed by t depots numbered n+1 to n+t, while
+// other problems list a single depot numbered 0 before the customers.
+// Problems with time windows end every vertex line with the earliest
+// and latest start of service (e l), which are the opening hours of
+// depots. Blank lines are ignored.
+func Parse(r io.Reader, name string) (*Instance, error) {
+	p, err := newParser(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &Instance{
+		Type:      entities.MDVRP,
+		Depots:    make(entities.Depots),
+		Customers: make(entities.Customers),
+		Metric:    entities.Euclidean{},
+	}
+
+	var numCustomers, numConstraints int
+	header := p.next()
+	if header == nil {
+		return nil, p.errorf(header, "Missing header")
+	}
+	values := []interface{}{&instance.MaxNumVehicles, &numCustomers, &numConstraints}
+	if len(header.fields) > 3 {
+		code := 0
+		values = append([]interface{}{&code}, values...)
+		if err := header.scan(4, values...); err != nil {
+			return nil, p.errorf(header, "Invalid header: %v", err)
+		}
+		problemType, ok := problemTypes[code]
+		if !ok {
+			return nil, p.errorf(header, "Unknown problem type %d", code)
+		}
+		instance.Type = problemType
+	} else if err := header.scan(3, values...); err != nil {
+		return nil, p.errorf(header, "Invalid header: %v", err)
+	}
+	if instance.MaxNumVehicles <= 0 || numCustomers <= 0 || numConstraints <= 0 {
+		return nil, p.errorf(header, "Header counts must be positive, got %d %d %d", instance.MaxNumVehicles, numCustomers, numConstraints)
+	}
+
+	for i := 0; i < numConstraints; i++ {
+		l := p.next()
+		if l == nil {
+			return nil, p.errorf(l, "Expected %d constraint lines, got %d", numConstraints, i)
+		}
+		constraint := Constraint{}
+		if err := l.scan(2, &constraint.MaxRouteDuration, &constraint.MaxVehicleLoad); err != nil {
+			return nil, p.errorf(l, "Invalid constraint: %v", err)
+		}
+		instance.Constraints = append(instance.Constraints, constraint)
+	}
+
+	if instance.Type.IsMultiDepot() {
+		for i, constraint := range instance.Constraints {
+			instance.Depots[i] = &entities.Depot{
+				MaxNumVehicles:   instance.MaxNumVehicles,
+				MaxRouteDuration: constraint.MaxRouteDuration,
+				MaxVehicleLoad:   constraint.MaxVehicleLoad,
+			}
+		}
+	} else {
+		// Single-depot problems list the depot first.
+		// Its constraints are those of the first day or vehicle type.
+		l := p.next()
+		if l == nil {
+			return nil, p.errorf(l, "Expected a depot line")
+		}
+		depot := &entities.Depot{
+			MaxNumVehicles:   instance.MaxNumVehicles,
+			MaxRouteDuration: instance.Constraints[0].MaxRouteDuration,
+			MaxVehicleLoad:   instance.Constraints[0].MaxVehicleLoad,
+		}
+		id := 0
+		if err := l.scan(3, &id, &depot.X, &depot.Y); err != nil {
+			return nil, p.errorf(l, "Invalid depot: %v", err)
+		}
+		if id != 0 {
+			return nil, p.errorf(l, "Depot ID must be 0, got %d", id)
+		}
+		if instance.Type.HasTimeWindows() {
+			if depot.OpeningHours, err = l.scanTimeWindow(); err != nil {
+				return nil, p.errorf(l, "Invalid depot: %v", err)
+			}
+		}
+		instance.Depots[0] = depot
+	}
+
+	for i := 0; i < numCustomers; i++ {
+		l := p.next()
+		if l == nil {
+			return nil, p.errorf(l, "Expected %d customer lines, got %d", numCustomers, i)
+		}
+		customer := &entities.Customer{}
+		if err := l.scan(5,
+			&customer.ID,
+			&customer.X,
+			&customer.Y,
+			&customer.ServiceDuration,
+			&customer.Demand,
+		); err != nil {
+			return nil, p.errorf(l, "Invalid customer: %v", err)
+		}
+		if err := l.scanCombinations(customer); err != nil {
+			return nil, p.errorf(l, "Invalid customer: %v", err)
+		}
+		if instance.Type.HasTimeWindows() {
+			if customer.TimeWindow, err = l.scanTimeWindow(); err != nil {
+				return nil, p.errorf(l, "Invalid customer: %v", err)
+			}
+		}
+		if customer.ID <= 0 || customer.ID > numCustomers {
+			return nil, p.errorf(l, "Customer ID %d is not between 1 and %d", customer.ID, numCustomers)
+		}
+		if _, ok := instance.Customers[customer.ID]; ok {
+			return nil, p.errorf(l, "Duplicate customer ID %d", customer.ID)
+		}
+		instance.Customers[customer.ID] = customer
+	}
+
+	if instance.Type.IsMultiDepot() {
+		if err := p.parseDepotCoordinates(instance.Depots, numCustomers, instance.Type.HasTimeWindows()); err != nil {
+			return nil, err
+		}
+	}
+
+	if l := p.next(); l != nil {
+		return nil, p.errorf(l, "Unexpected line after the last vertex")
+	}
+
+	return instance, nil
+}
+
+// parseDepotCoordinates parses the coordinates of multiple
+// depots, numbered from numCustomers+1, and their opening
+// hours if the problem has time windows.
+func (p *parser) parseDepotCoordinates(depots entities.Depots, numCustomers int, timeWindows bool) error {
+	positioned := map[int]bool{}
+	for i := 0; i < len(depots); i++ {
+		l := p.next()
+		if l == nil {
+			return p.errorf(l, "Expected %d depot coordinate lines, got %d", len(depots), i)
+		}
+		var id int
+		var x, y float64
+		if err := l.scan(3, &id, &x, &y); err != nil {
+			return p.errorf(l, "Invalid depot coordinates: %v", err)
+		}
+
+		depotID := id - numCustomers - 1
+		if depotID < 0 || depotID >= len(depots) {
+			return p.errorf(l, "Depot ID %d is not between %d and %d", id, numCustomers+1, numCustomers+len(depots))
+		}
+		if positioned[depotID] {
+			return p.errorf(l, "Duplicate depot ID %d", id)
+		}
+		positioned[depotID] = true
+		depots[depotID].X, depots[depotID].Y = x, y
+
+		if timeWindows {
+			openingHours, err := l.scanTimeWindow()
+			if err != nil {
+				return p.errorf(l, "Invalid depot: %v", err)
+			}
+			depots[depotID].OpeningHours = openingHours
+		}
+	}
+
+	return nil
+}
+
+// line is a non-blank line of a problem file.
+type line struct {
+	number int
+	fields []string
+}
+
+// scan parses the line's first fields into the provided values,
+// which must be *int or *float64. The line must hold at least
+// min fields; additional fields are ignored.
+func (l *line) scan(min int, values ...interface{}) error {
+	if len(l.fields) < min {
+		return fmt.Errorf("expected at least %d fields, got %d", min, len(l.fields))
+	}
+
+	for i, value := range values {
+		field := l.fields[i]
+		switch v := value.(type) {
+		case *int:
+			n, err := strconv.Atoi(field)
+			if err != nil {
+				return fmt.Errorf("field %d: %q is not an integer", i+1, field)
+			}
+			*v = n
+		case *float64:
+			f, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return fmt.Errorf("field %d: %q is not a number", i+1, field)
+			}
+			*v = f
+		default:
+			panic(fmt.Errorf("Unsupported value type %T", value))
+		}
+	}
+
+	return nil
+}
+
+// scanCombinations parses the customer's optional frequency
+// and visit combinations (f a list), following the first 5 fields.
+func (l *line) scanCombinations(customer *entities.Customer) error {
+	if len(l.fields) <= 5 {
+		return nil
+	}
+
+	numCombinations := 0
+	if err := (&line{fields: l.fields[5:]}).scan(2, &customer.Frequency, &numCombinations); err != nil {
+		return err
+	}
+	if len(l.fields) < 7+numCombinations {
+		return fmt.Errorf("expected %d visit combinations, got %d", numCombinations, len(l.fields)-7)
+	}
+
+	customer.Combinations = make([]int, numCombinations)
+	for i, field := range l.fields[7 : 7+numCombinations] {
+		combination, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("field %d: %q is not an integer", 8+i, field)
+		}
+		customer.Combinations[i] = combination
+	}
+
+	return nil
+}
+
+// scanTimeWindow parses the time window (e l) ending the
+// line, following at least the first 7 fields.
+func (l *line) scanTimeWindow() (*entities.TimeWindow, error) {
+	if len(l.fields) < 9 {
+		return nil, fmt.Errorf("expected a time window after at least 7 fields, got %d fields", len(l.fields))
+	}
+
+	tw := &entities.TimeWindow{}
+	if err := (&line{fields: l.fields[len(l.fields)-2:]}).scan(2, &tw.Start, &tw.End); err != nil {
+		return nil, fmt.Errorf("time window: %v", err)
+	}
+	if tw.Start > tw.End {
+		return nil, fmt.Errorf("time window %v starts after it ends", tw)
+	}
+
+	return tw, nil
+}
+
+// parser hands out the non-blank lines of a problem file in order.
+type parser struct {
+	name     string
+	lines    []*line
+	position int
+}
+
+// newParser reads all non-blank lines from r.
+func newParser(r io.Reader, name string) (*parser, error) {
+	p := &parser{name: name}
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		p.lines = append(p.lines, &line{number: number, fields: fields})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{File: name, Err: err}
+	}
+
+	return p, nil
+}
+
+// next returns the next line, or nil at the end of the file.
+func (p *parser) next() *line {
+	if p.position >= len(p.lines) {
+		return nil
+	}
+	l := p.lines[p.position]
+	p.position++
+	return l
+}
+
+// errorf returns a parse error for the line. A nil line
+// refers to the end of the file.
+func (p *parser) errorf(l *line, format string, args ...interface{}) error {
+	err := &ParseError{File: p.name, Err: fmt.Errorf(format, args...)}
+	if l != nil {
+		err.Line = l.number
+	} else {
+		err.Err = fmt.Errorf("Unexpected end of file: %v", err.Err)
+	}
+	return err
+}
+
+// fileErrorf returns a parse error for the file as a whole.
+func (p *parser) fileErrorf(format string, args ...interface{}) error {
+	return &ParseError{File: p.name, Err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,117 @@
+package problem
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestParse(t *testing.T) {
+	text := `2 3 2
+0 80
+100 60
+
+1 10 20 5 7
+2 30 40 5 8 1 2 1 2
+3 50 60 0 9
+4 0 0
+5 70 80
+`
+	instance, err := Parse(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Type != entities.MDVRP || instance.MaxNumVehicles != 2 {
+		t.Errorf("Expected an MDVRP with 2 vehicles, got %v with %d", instance.Type, instance.MaxNumVehicles)
+	}
+	if len(instance.Depots) != 2 || len(instance.Customers) != 3 {
+		t.Fatalf("Expected 2 depots and 3 customers, got %d and %d", len(instance.Depots), len(instance.Customers))
+	}
+
+	depot := instance.Depots[1]
+	if depot.X != 70 || depot.Y != 80 || depot.MaxRouteDuration != 100 || depot.MaxVehicleLoad != 60 || depot.MaxNumVehicles != 2 {
+		t.Errorf("Unexpected depot %v", depot)
+	}
+	customer := instance.Customers[2]
+	if customer.X != 30 || customer.Y != 40 || customer.ServiceDuration != 5 || customer.Demand != 8 {
+		t.Errorf("Unexpected customer %+v", customer)
+	}
+	if customer.Frequency != 1 || len(customer.Combinations) != 2 {
+		t.Errorf("Expected frequency 1 and 2 combinations, got %d and %v", customer.Frequency, customer.Combinations)
+	}
+}
+
+func TestParseTimeWindows(t *testing.T) {
+	text := `4 2 2 1
+0 100
+0 40 50 0 0 0 0 0 1000
+1 45 68 10 10 1 1 1 912 967
+2 45 70 10 30 1 1 1 825 870
+`
+	instance, err := Parse(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Type != entities.VRPTW {
+		t.Errorf("Expected a VRPTW, got %v", instance.Type)
+	}
+	if hours := instance.Depots[0].OpeningHours; hours == nil || *hours != (entities.TimeWindow{Start: 0, End: 1000}) {
+		t.Errorf("Expected opening hours 0-1000, got %v", hours)
+	}
+	if tw := instance.Customers[2].TimeWindow; tw == nil || *tw != (entities.TimeWindow{Start: 825, End: 870}) {
+		t.Errorf("Expected time window 825-870, got %v", tw)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		line int
+		err  string
+	}{
+		{"empty", "", 0, "Missing header"},
+		{"invalid header", "2 x 1\n", 1, "Invalid header"},
+		{"unknown type", "9 2 1 1\n", 1, "Unknown problem type 9"},
+		{"zero counts", "0 1 1\n", 1, "must be positive"},
+		{"missing constraints", "2 1 2\n0 80\n", 0, "Expected 2 constraint lines, got 1"},
+		{"invalid customer", "2 1 1\n0 80\n1 10 x 0 5\n", 3, "field 3"},
+		{"customer ID out of range", "2 1 1\n0 80\n2 10 20 0 5\n", 3, "not between 1 and 1"},
+		{"duplicate customer", "2 2 1\n0 80\n1 10 20 0 5\n1 10 20 0 5\n", 4, "Duplicate customer ID 1"},
+		{"depot ID out of range", "2 1 1\n0 80\n1 10 20 0 5\n3 0 0\n", 4, "not between 2 and 2"},
+		{"single depot ID", "0 2 1 1\n0 80\n1 0 0\n", 3, "Depot ID must be 0"},
+		{"missing time window", "4 2 1 1\n0 80\n0 0 0 0 0 0 0\n", 3, "expected a time window"},
+		{"reversed time window", "4 2 1 1\n0 80\n0 0 0 0 0 0 0 9 1\n", 3, "starts after it ends"},
+		{"trailing line", "2 1 1\n0 80\n1 10 20 0 5\n2 0 0\n2 0 0\n", 5, "Unexpected line"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.text), "test")
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a parse error, got %v", err)
+			}
+			if parseErr.File != "test" || parseErr.Line != test.line {
+				t.Errorf("Expected the error at test:%d, got %s:%d", test.line, parseErr.File, parseErr.Line)
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected an error containing %q, got %q", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadInstance(t *testing.T) {
+	instance, err := LoadInstance("../../problems/p01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instance.Depots) != 4 || len(instance.Customers) != 50 {
+		t.Errorf("Expected 4 depots and 50 customers, got %d and %d", len(instance.Depots), len(instance.Customers))
+	}
+}
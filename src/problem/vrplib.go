@@ -0,0 +1,367 @@
+package problem
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// LoadVRP reads a problem instance in the TSPLIB/CVRPLIB
+// (.vrp) format from a file in the specified filePath.
+func LoadVRP(filePath string) (*Instance, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseVRP(file, filePath)
+}
+
+// vrpFile holds the raw contents of a .vrp file.
+type vrpFile struct {
+	specification map[string]string
+
+	coordinates map[int][2]float64
+	demands     map[int]float64
+	depots      []int
+	weights     []float64
+}
+
+// fleetSize matches the number of vehicles in CVRPLIB
+// instance names, e.g. "A-n32-k5".
+var fleetSize = regexp.MustCompile(`-k(\d+)`)
+
+// ParseVRP parses a problem in the TSPLIB/CVRPLIB format. The name
+// is used to give context to errors, e.g. the file name.
+//
+// Nodes listed in the DEPOT_SECTION become depots, numbered from 0
+// in the order they are listed, while all other nodes become
+// customers with their node number as ID. The number of vehicles per
+// depot is read from VEHICLES, the instance name (e.g. "-k5") or
+// else estimated from the total demand. Supported edge weight types
+// are EUC_2D, CEIL_2D and EXPLICIT. Every node must have coordinates,
+// unless the edge weights are EXPLICIT.
+func ParseVRP(r io.Reader, name string) (*Instance, error) {
+	p, err := newParser(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := p.parseVRPFile()
+	if err != nil {
+		return nil, err
+	}
+
+	dimension, err := f.intSpec("DIMENSION")
+	if err != nil || dimension <= 0 {
+		return nil, p.fileErrorf("Missing or invalid DIMENSION")
+	}
+	capacity, err := f.floatSpec("CAPACITY")
+	if err != nil || capacity <= 0 {
+		return nil, p.fileErrorf("Missing or invalid CAPACITY")
+	}
+	if len(f.depots) == 0 {
+		// Without a depot section, node 1 is the depot.
+		f.depots = []int{1}
+	}
+
+	maxDuration := 0.0
+	if _, ok := f.specification["DISTANCE"]; ok {
+		if maxDuration, err = f.floatSpec("DISTANCE"); err != nil {
+			return nil, p.fileErrorf("Invalid DISTANCE: %v", err)
+		}
+	}
+	serviceDuration := 0.0
+	if _, ok := f.specification["SERVICE_TIME"]; ok {
+		if serviceDuration, err = f.floatSpec("SERVICE_TIME"); err != nil {
+			return nil, p.fileErrorf("Invalid SERVICE_TIME: %v", err)
+		}
+	}
+
+	instance := &Instance{
+		Type:      entities.VRP,
+		Depots:    make(entities.Depots),
+		Customers: make(entities.Customers),
+	}
+	if len(f.depots) > 1 {
+		instance.Type = entities.MDVRP
+	}
+
+	isDepot := map[int]bool{}
+	for depotID, node := range f.depots {
+		if node < 1 || node > dimension {
+			return nil, p.fileErrorf("Depot node %d is not between 1 and %d", node, dimension)
+		}
+		if isDepot[node] {
+			return nil, p.fileErrorf("Duplicate depot node %d", node)
+		}
+		isDepot[node] = true
+
+		instance.Constraints = append(instance.Constraints, Constraint{
+			MaxRouteDuration: maxDuration,
+			MaxVehicleLoad:   capacity,
+		})
+		instance.Depots[depotID] = &entities.Depot{
+			X:                f.coordinates[node][0],
+			Y:                f.coordinates[node][1],
+			MaxRouteDuration: maxDuration,
+			MaxVehicleLoad:   capacity,
+		}
+	}
+
+	totalDemand := 0.0
+	for node := 1; node <= dimension; node++ {
+		if isDepot[node] {
+			continue
+		}
+		if _, ok := f.demands[node]; !ok {
+			return nil, p.fileErrorf("Missing demand of node %d", node)
+		}
+		instance.Customers[node] = &entities.Customer{
+			ID:              node,
+			X:               f.coordinates[node][0],
+			Y:               f.coordinates[node][1],
+			ServiceDuration: serviceDuration,
+			Demand:          f.demands[node],
+		}
+		totalDemand += f.demands[node]
+	}
+	if len(instance.Customers) == 0 {
+		return nil, p.fileErrorf("No customers")
+	}
+
+	instance.MaxNumVehicles = int(math.Ceil(totalDemand/capacity)) + 1
+	if _, ok := f.specification["VEHICLES"]; ok {
+		if instance.MaxNumVehicles, err = f.intSpec("VEHICLES"); err != nil {
+			return nil, p.fileErrorf("Invalid VEHICLES: %v", err)
+		}
+	} else if match := fleetSize.FindStringSubmatch(f.specification["NAME"]); match != nil {
+		instance.MaxNumVehicles, _ = strconv.Atoi(match[1])
+	}
+	for _, depot := range instance.Depots {
+		depot.MaxNumVehicles = instance.MaxNumVehicles
+	}
+
+	edgeWeightType := f.specification["EDGE_WEIGHT_TYPE"]
+	switch edgeWeightType {
+	case "EUC_2D":
+		instance.Metric = entities.RoundedEuclidean{}
+	case "CEIL_2D":
+		instance.Metric = entities.CeiledEuclidean{}
+	case "EXPLICIT":
+		values, err := f.weightMatrix(dimension)
+		if err != nil {
+			return nil, p.fileErrorf("Invalid EDGE_WEIGHT_SECTION: %v", err)
+		}
+		matrix := entities.NewDistanceMatrix(values)
+		for depotID, node := range f.depots {
+			matrix.SetIndex(instance.Depots[depotID], node-1)
+		}
+		for node, customer := range instance.Customers {
+			matrix.SetIndex(customer, node-1)
+		}
+		instance.Metric = matrix
+	default:
+		return nil, p.fileErrorf("Unsupported EDGE_WEIGHT_TYPE %q", edgeWeightType)
+	}
+
+	// Distances are measured between coordinates,
+	// unless the edge weights are explicit.
+	if edgeWeightType != "EXPLICIT" {
+		for node := 1; node <= dimension; node++ {
+			if _, ok := f.coordinates[node]; !ok {
+				return nil, p.fileErrorf("Missing coordinates of node %d", node)
+			}
+		}
+	}
+
+	return instance, nil
+}
+
+// parseVRPFile reads the specification and data sections of a .vrp file.
+func (p *parser) parseVRPFile() (*vrpFile, error) {
+	f := &vrpFile{
+		specification: map[string]string{},
+		coordinates:   map[int][2]float64{},
+		demands:       map[int]float64{},
+	}
+
+	for l := p.next(); l != nil; l = p.next() {
+		keyword := strings.TrimSuffix(l.fields[0], ":")
+		switch keyword {
+		case "EOF":
+			return f, nil
+		case "NODE_COORD_SECTION", "DISPLAY_DATA_SECTION":
+			err := p.scanSection(func(l *line) error {
+				var node int
+				var x, y float64
+				if err := l.scan(3, &node, &x, &y); err != nil {
+					return err
+				}
+				// Node coordinates take precedence over display data.
+				if _, ok := f.coordinates[node]; !ok || keyword == "NODE_COORD_SECTION" {
+					f.coordinates[node] = [2]float64{x, y}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "DEMAND_SECTION":
+			err := p.scanSection(func(l *line) error {
+				var node int
+				var demand float64
+				if err := l.scan(2, &node, &demand); err != nil {
+					return err
+				}
+				f.demands[node] = demand
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "DEPOT_SECTION":
+			err := p.scanSection(func(l *line) error {
+				for _, field := range l.fields {
+					node, err := strconv.Atoi(field)
+					if err != nil {
+						return fmt.Errorf("%q is not an integer", field)
+					}
+					if node == -1 {
+						return nil
+					}
+					f.depots = append(f.depots, node)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		case "EDGE_WEIGHT_SECTION":
+			err := p.scanSection(func(l *line) error {
+				for _, field := range l.fields {
+					weight, err := strconv.ParseFloat(field, 64)
+					if err != nil {
+						return fmt.Errorf("%q is not a number", field)
+					}
+					f.weights = append(f.weights, weight)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			// Specification lines are "KEY : VALUE", with or
+			// without spaces around the colon.
+			text := strings.Join(l.fields, " ")
+			i := strings.Index(text, ":")
+			if i < 0 {
+				return nil, p.errorf(l, "Unknown section %q", keyword)
+			}
+			key := strings.TrimSpace(text[:i])
+			f.specification[key] = strings.TrimSpace(text[i+1:])
+		}
+	}
+
+	return f, nil
+}
+
+// scanSection passes the lines of a data section to scan.
+// The section ends before the next line starting with a keyword.
+func (p *parser) scanSection(scan func(l *line) error) error {
+	for p.position < len(p.lines) {
+		l := p.lines[p.position]
+		if isKeyword(l.fields[0]) {
+			return nil
+		}
+		p.position++
+		if err := scan(l); err != nil {
+			return p.errorf(l, "Invalid section line: %v", err)
+		}
+	}
+	return nil
+}
+
+// isKeyword returns true if the field is a TSPLIB keyword,
+// i.e. an upper case word that is not a number.
+func isKeyword(field string) bool {
+	field = strings.TrimSuffix(field, ":")
+	if field == "" {
+		return false
+	}
+	for _, c := range field {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return field[0] >= 'A' && field[0] <= 'Z'
+}
+
+// intSpec returns the specification value of the key as an integer.
+func (f *vrpFile) intSpec(key string) (int, error) {
+	return strconv.Atoi(f.specification[key])
+}
+
+// floatSpec returns the specification value of the key as a number.
+func (f *vrpFile) floatSpec(key string) (float64, error) {
+	return strconv.ParseFloat(f.specification[key], 64)
+}
+
+// weightMatrix builds the full distance matrix from the edge weights
+// following the EDGE_WEIGHT_FORMAT. Column formats are the transposed
+// row formats, which are equal for the symmetric matrices they describe.
+func (f *vrpFile) weightMatrix(dimension int) ([][]float64, error) {
+	values := make([][]float64, dimension)
+	for i := range values {
+		values[i] = make([]float64, dimension)
+	}
+
+	// cells reports whether the format lists a weight for cell (i, j).
+	var cells func(i, j int) bool
+	symmetric := true
+	switch format := f.specification["EDGE_WEIGHT_FORMAT"]; format {
+	case "FULL_MATRIX":
+		cells = func(i, j int) bool { return true }
+		symmetric = false
+	case "UPPER_ROW", "LOWER_COL":
+		cells = func(i, j int) bool { return i < j }
+	case "LOWER_ROW", "UPPER_COL":
+		cells = func(i, j int) bool { return i > j }
+	case "UPPER_DIAG_ROW", "LOWER_DIAG_COL":
+		cells = func(i, j int) bool { return i <= j }
+	case "LOWER_DIAG_ROW", "UPPER_DIAG_COL":
+		cells = func(i, j int) bool { return i >= j }
+	default:
+		return nil, fmt.Errorf("unsupported EDGE_WEIGHT_FORMAT %q", format)
+	}
+
+	k := 0
+	for i := 0; i < dimension; i++ {
+		for j := 0; j < dimension; j++ {
+			if !cells(i, j) {
+				continue
+			}
+			if k >= len(f.weights) {
+				return nil, fmt.Errorf("expected more than %d weights", len(f.weights))
+			}
+			values[i][j] = f.weights[k]
+			if symmetric {
+				values[j][i] = f.weights[k]
+			}
+			k++
+		}
+	}
+	if k != len(f.weights) {
+		return nil, fmt.Errorf("expected %d weights, got %d", k, len(f.weights))
+	}
+
+	return values, nil
+}
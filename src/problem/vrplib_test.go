@@ -0,0 +1,120 @@
+package problem
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestParseVRP(t *testing.T) {
+	text := `NAME : A-n4-k2
+TYPE : CVRP
+DIMENSION : 4
+EDGE_WEIGHT_TYPE : EUC_2D
+CAPACITY : 100
+NODE_COORD_SECTION
+1 0 0
+2 3 4
+3 6 8
+4 0 5
+DEMAND_SECTION
+1 0
+2 10
+3 20
+4 30
+DEPOT_SECTION
+1
+-1
+EOF
+`
+	instance, err := ParseVRP(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Type != entities.VRP || instance.MaxNumVehicles != 2 {
+		t.Errorf("Expected a VRP with 2 vehicles, got %v with %d", instance.Type, instance.MaxNumVehicles)
+	}
+	if _, ok := instance.Metric.(entities.RoundedEuclidean); !ok {
+		t.Errorf("Expected a rounded euclidean metric, got %T", instance.Metric)
+	}
+	if len(instance.Depots) != 1 || len(instance.Customers) != 3 {
+		t.Fatalf("Expected 1 depot and 3 customers, got %d and %d", len(instance.Depots), len(instance.Customers))
+	}
+	if depot := instance.Depots[0]; depot.MaxVehicleLoad != 100 || depot.X != 0 || depot.Y != 0 {
+		t.Errorf("Unexpected depot %v", depot)
+	}
+	if customer := instance.Customers[3]; customer.ID != 3 || customer.X != 6 || customer.Demand != 20 {
+		t.Errorf("Unexpected customer %+v", customer)
+	}
+}
+
+func TestParseVRPExplicit(t *testing.T) {
+	text := `NAME: E-n3
+DIMENSION: 3
+CAPACITY: 10
+VEHICLES: 4
+EDGE_WEIGHT_TYPE: EXPLICIT
+EDGE_WEIGHT_FORMAT: LOWER_ROW
+EDGE_WEIGHT_SECTION
+5
+7 9
+DEMAND_SECTION
+1 0
+2 1
+3 2
+EOF
+`
+	instance, err := ParseVRP(strings.NewReader(text), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.MaxNumVehicles != 4 {
+		t.Errorf("Expected 4 vehicles, got %d", instance.MaxNumVehicles)
+	}
+	depot, c2, c3 := instance.Depots[0], instance.Customers[2], instance.Customers[3]
+	tests := []struct {
+		name string
+		a, b entities.Location
+		want float64
+	}{
+		{"depot to 2", depot, c2, 5},
+		{"depot to 3", depot, c3, 7},
+		{"2 to 3", c2, c3, 9},
+		{"3 to 2", c3, c2, 9},
+	}
+	for _, test := range tests {
+		if d := instance.Metric.Distance(test.a, test.b); d != test.want {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, d)
+		}
+	}
+}
+
+func TestParseVRPErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"missing dimension", "CAPACITY: 10\n", "DIMENSION"},
+		{"missing capacity", "DIMENSION: 2\n", "CAPACITY"},
+		{"missing demand", "DIMENSION: 2\nCAPACITY: 10\nEDGE_WEIGHT_TYPE: EUC_2D\nDEMAND_SECTION\n1 0\n", "Missing demand of node 2"},
+		{"depot out of range", "DIMENSION: 2\nCAPACITY: 10\nDEPOT_SECTION\n3\n-1\n", "Depot node 3"},
+		{"unsupported weights", "DIMENSION: 2\nCAPACITY: 10\nEDGE_WEIGHT_TYPE: GEO\nDEMAND_SECTION\n1 0\n2 1\n", "Unsupported EDGE_WEIGHT_TYPE"},
+		{"too few weights", "DIMENSION: 3\nCAPACITY: 10\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: LOWER_ROW\nEDGE_WEIGHT_SECTION\n5\nDEMAND_SECTION\n1 0\n2 1\n3 1\n", "expected more than 1 weights"},
+		{"invalid section line", "DIMENSION: 2\nCAPACITY: 10\nDEMAND_SECTION\n1 x\n", "Invalid section line"},
+		{"unknown section", "DIMENSION: 2\nFOO_SECTION\n", "Unknown section"},
+		{"missing coordinates", "DIMENSION: 2\nCAPACITY: 10\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\nDEMAND_SECTION\n1 0\n2 1\n", "Missing coordinates of node 2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseVRP(strings.NewReader(test.text), "test")
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
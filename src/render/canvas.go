@@ -0,0 +1,84 @@
+package render
+
+import (
+	"math"
+	"sync"
+
+	"github.com/tfriedel6/canvas"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+var (
+	font     *canvas.Font
+	fontOnce sync.Once
+)
+
+// CanvasPainter paints onto a canvas, e.g. a window's canvas
+// or an offscreen canvas backed by an image.
+type CanvasPainter struct {
+	*canvas.Canvas
+}
+
+// Size returns the width and height of the canvas.
+func (cp CanvasPainter) Size() (w, h float64) {
+	return float64(cp.Width()), float64(cp.Height())
+}
+
+// Fill fills the whole canvas with the color.
+func (cp CanvasPainter) Fill(color string) {
+	w, h := cp.Size()
+	cp.SetFillStyle(color)
+	cp.FillRect(0, 0, w, h)
+}
+
+// Rect fills a rectangle with its top left corner at (x, y).
+func (cp CanvasPainter) Rect(x, y, w, h float64, color string) {
+	cp.SetFillStyle(color)
+	cp.FillRect(x, y, w, h)
+}
+
+// Circle strokes a circle centered at (x, y).
+func (cp CanvasPainter) Circle(x, y, radius float64, color string, lineWidth float64) {
+	cp.SetStrokeStyle(color)
+	cp.SetLineWidth(lineWidth)
+	cp.BeginPath()
+	cp.Arc(x, y, radius, 0, math.Pi*2, false)
+	cp.Stroke()
+}
+
+// Path strokes lines through the points.
+func (cp CanvasPainter) Path(points [][2]float64, color string, lineWidth float64, dash []float64, closed bool) {
+	if len(points) == 0 {
+		return
+	}
+
+	cp.SetStrokeStyle(color)
+	cp.SetLineWidth(lineWidth)
+	cp.SetLineDash(dash)
+	defer cp.SetLineDash(nil)
+	cp.BeginPath()
+	cp.MoveTo(points[0][0], points[0][1])
+	for _, point := range points[1:] {
+		cp.LineTo(point[0], point[1])
+	}
+	if closed {
+		cp.ClosePath()
+	}
+	cp.Stroke()
+}
+
+// Text writes text with its top left corner at (x, y).
+func (cp CanvasPainter) Text(x, y float64, text string, color string, size float64) {
+	// Fonts are parsed once and shared by all canvases.
+	fontOnce.Do(func() {
+		var err error
+		if font, err = cp.LoadFont(gomono.TTF); err != nil {
+			panic(err)
+		}
+	})
+
+	cp.SetFont(font, size)
+	cp.SetFillStyle(color)
+	cp.SetTextBaseline(canvas.Top)
+	cp.FillText(text, x, y)
+}
@@ -1,4 +1,4 @@
-package visualizer
+package render
 
 import (
 	"math"
@@ -0,0 +1,156 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// Colors of edges in comparisons.
+const (
+	sharedEdgeColor = "#FFF3"
+	onlyAEdgeColor  = "#4CF"
+	onlyBEdgeColor  = "#F84"
+)
+
+// Comparison compares two solutions of the same problem, e.g. the
+// current best agent and a best-known solution. Edges found in only
+// one of the solutions are highlighted.
+type Comparison struct {
+	Depots    entities.Depots
+	Customers entities.Customers
+
+	A, B           *solver.Agent
+	LabelA, LabelB string
+
+	// Directed tells if reversing a route may change it
+	// (see solver.Directed).
+	Directed bool
+
+	// Overlay draws both solutions on top of each
+	// other instead of side by side.
+	Overlay bool
+
+	// View is the part of the problem to show when overlaid.
+	// All depots and customers are shown if it is nil. Side
+	// by side, only whether the view is flipped is used.
+	View *View
+}
+
+// Draw draws the comparison with a summary of the differences.
+func (c Comparison) Draw(p Painter) {
+	diff := solver.Diff(c.A.Dna, c.B.Dna, c.Depots, c.Directed)
+	w, h := p.Size()
+
+	if c.Overlay {
+		v := NewView(c.Depots, c.Customers, w, h)
+		if c.View != nil {
+			v = *c.View
+		}
+		Scene{Depots: c.Depots, Customers: c.Customers, View: &v}.Draw(p)
+		c.drawEdges(p, v, diff.SharedEdges, sharedEdgeColor)
+		c.drawEdges(p, v, diff.OnlyA, onlyAEdgeColor)
+		c.drawEdges(p, v, diff.OnlyB, onlyBEdgeColor)
+		p.Text(10, 30, c.LabelB, onlyBEdgeColor, 14)
+	} else {
+		left := subPainter{Painter: p, w: w / 2, h: h}
+		right := subPainter{Painter: p, x: w / 2, w: w / 2, h: h}
+		for _, side := range []struct {
+			p     subPainter
+			only  map[solver.Edge]bool
+			color string
+		}{
+			{left, diff.OnlyA, onlyAEdgeColor},
+			{right, diff.OnlyB, onlyBEdgeColor},
+		} {
+			v := NewView(c.Depots, c.Customers, w/2, h)
+			v.FlipY = c.View != nil && c.View.FlipY
+			Scene{Depots: c.Depots, Customers: c.Customers, View: &v}.Draw(side.p)
+			c.drawEdges(side.p, v, diff.SharedEdges, sharedEdgeColor)
+			c.drawEdges(side.p, v, side.only, side.color)
+		}
+		p.Path([][2]float64{{w / 2, 0}, {w / 2, h}}, "#FFF5", 1, nil, false)
+		right.Text(10, 10, c.LabelB, onlyBEdgeColor, 14)
+	}
+
+	p.Text(10, 10, c.LabelA, onlyAEdgeColor, 14)
+	Tooltip(p, 0, h, c.summary(diff)...)
+}
+
+// summary describes the cost and differences of the solutions.
+func (c Comparison) summary(diff solver.DNADiff) []string {
+	numRoutes := func(dna solver.DNA) (n int) {
+		for _, route := range dna {
+			if len(route.Path) > 0 {
+				n++
+			}
+		}
+		return
+	}
+
+	delta := c.B.Fitness.Distance - c.A.Fitness.Distance
+	lines := []string{
+		fmt.Sprintf("%s: distance %.2f, %d of %d routes differ", c.LabelA, c.A.Fitness.Distance, len(diff.RoutesA), numRoutes(c.A.Dna)),
+		fmt.Sprintf("%s: distance %.2f, %d of %d routes differ", c.LabelB, c.B.Fitness.Distance, len(diff.RoutesB), numRoutes(c.B.Dna)),
+		fmt.Sprintf("Delta: %+.2f", delta),
+		fmt.Sprintf("Edges: %d shared, %d only in %s, %d only in %s", len(diff.SharedEdges), len(diff.OnlyA), c.LabelA, len(diff.OnlyB), c.LabelB),
+	}
+	if c.A.Fitness.Distance > 0 {
+		lines[2] += fmt.Sprintf(" (%+.2f%%)", 100*delta/c.A.Fitness.Distance)
+	}
+	return lines
+}
+
+// drawEdges draws the edges as lines between their nodes.
+func (c Comparison) drawEdges(p Painter, v View, edges map[solver.Edge]bool, color string) {
+	for edge := range edges {
+		points := [][2]float64{}
+		for _, node := range edge {
+			x, y := v.Position(nodeLocation(c.Depots, c.Customers, node).GetPosition())
+			points = append(points, [2]float64{x, y})
+		}
+		p.Path(points, color, 1, nil, false)
+	}
+}
+
+// subPainter paints onto a part of another painter's surface,
+// with its top left corner at (x, y).
+type subPainter struct {
+	Painter
+	x, y, w, h float64
+}
+
+// Size returns the width and height of the part.
+func (sp subPainter) Size() (w, h float64) {
+	return sp.w, sp.h
+}
+
+// Fill fills the part with the color.
+func (sp subPainter) Fill(color string) {
+	sp.Painter.Rect(sp.x, sp.y, sp.w, sp.h, color)
+}
+
+// Rect fills a rectangle with its top left corner at (x, y).
+func (sp subPainter) Rect(x, y, w, h float64, color string) {
+	sp.Painter.Rect(sp.x+x, sp.y+y, w, h, color)
+}
+
+// Circle strokes a circle centered at (x, y).
+func (sp subPainter) Circle(x, y, radius float64, color string, lineWidth float64) {
+	sp.Painter.Circle(sp.x+x, sp.y+y, radius, color, lineWidth)
+}
+
+// Path strokes lines through the points.
+func (sp subPainter) Path(points [][2]float64, color string, lineWidth float64, dash []float64, closed bool) {
+	moved := make([][2]float64, len(points))
+	for i, point := range points {
+		moved[i] = [2]float64{sp.x + point[0], sp.y + point[1]}
+	}
+	sp.Painter.Path(moved, color, lineWidth, dash, closed)
+}
+
+// Text writes text with its top left corner at (x, y).
+func (sp subPainter) Text(x, y float64, text string, color string, size float64) {
+	sp.Painter.Text(sp.x+x, sp.y+y, text, color, size)
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tfriedel6/canvas"
+	"github.com/tfriedel6/canvas/backend/softwarebackend"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// WritePNG draws the scene offscreen and writes it as a PNG image.
+func WritePNG(w io.Writer, width, height int, depots entities.Depots, customers entities.Customers, agent *solver.Agent) error {
+	backend := softwarebackend.New(width, height)
+	Draw(CanvasPainter{canvas.New(backend)}, depots, customers, agent)
+
+	return png.Encode(w, backend.Image)
+}
+
+// WriteSVG draws the scene as an SVG document.
+func WriteSVG(w io.Writer, width, height int, depots entities.Depots, customers entities.Customers, agent *solver.Agent) error {
+	sp := &svgPainter{w: w, size: [2]float64{float64(width), float64(height)}}
+
+	sp.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	Draw(sp, depots, customers, agent)
+	sp.printf("</svg>\n")
+
+	return sp.err
+}
+
+// WriteFile draws the scene to a PNG or SVG file,
+// depending on the extension of the filePath.
+func WriteFile(filePath string, width, height int, depots entities.Depots, customers entities.Customers, agent *solver.Agent) error {
+	write := WritePNG
+	switch filepath.Ext(filePath) {
+	case ".png":
+	case ".svg":
+		write = WriteSVG
+	default:
+		return fmt.Errorf("Unknown image format %q", filepath.Ext(filePath))
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, width, height, depots, customers, agent); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
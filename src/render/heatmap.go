@@ -0,0 +1,79 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// Heatmap shows how often edges are traveled across the population.
+// Frequent edges are drawn thicker and brighter, so a population
+// that has converged shows few, bright edges.
+type Heatmap struct {
+	Depots    entities.Depots
+	Customers entities.Customers
+
+	// Frequency is the share of agents traveling each edge.
+	Frequency map[solver.Edge]float64
+
+	// View is the part of the problem to show.
+	// All depots and customers are shown if it is nil.
+	View *View
+}
+
+// Draw draws the heatmap.
+func (hm Heatmap) Draw(p Painter) {
+	w, h := p.Size()
+	v := NewView(hm.Depots, hm.Customers, w, h)
+	if hm.View != nil {
+		v = *hm.View
+	}
+	Scene{Depots: hm.Depots, Customers: hm.Customers, View: &v}.Draw(p)
+
+	// Draw frequent edges last, on top of the rare ones.
+	edges := []solver.Edge{}
+	for edge := range hm.Frequency {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return hm.Frequency[edges[i]] < hm.Frequency[edges[j]]
+	})
+
+	for _, edge := range edges {
+		f := hm.Frequency[edge]
+
+		points := [][2]float64{}
+		for _, node := range edge {
+			x, y := v.Position(nodeLocation(hm.Depots, hm.Customers, node).GetPosition())
+			points = append(points, [2]float64{x, y})
+		}
+		p.Path(points, heatColor(f), 0.5+3.5*f, nil, false)
+	}
+
+	Caption(p,
+		fmt.Sprintf("Population edges: %d", len(edges)),
+		"Thicker and brighter edges are shared by more agents",
+	)
+}
+
+// heatColor returns a color from a transparent dark blue
+// (frequency 0) to an opaque yellow (frequency 1).
+func heatColor(f float64) string {
+	f = math.Max(0, math.Min(1, f))
+	mix := func(a, b float64) int {
+		return int(math.Round(a + (b-a)*f))
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", mix(0x20, 0xFF), mix(0x50, 0xDD), mix(0xFF, 0x55), mix(0x30, 0xFF))
+}
+
+// nodeLocation returns the location of an edge node.
+// Depots are identified by -(depot ID + 1).
+func nodeLocation(depots entities.Depots, customers entities.Customers, node int) entities.Location {
+	if node < 0 {
+		return depots[-node-1]
+	}
+	return customers[node]
+}
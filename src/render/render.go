@@ -0,0 +1,198 @@
+package render
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// Painter draws primitive shapes onto a surface.
+// Scenes are drawn through a painter so that they look
+// the same on screen and in image files.
+type Painter interface {
+	// Size returns the width and height of the surface.
+	Size() (w, h float64)
+
+	// Fill fills the whole surface with the color.
+	Fill(color string)
+
+	// Rect fills a rectangle with its top left corner at (x, y).
+	Rect(x, y, w, h float64, color string)
+
+	// Circle strokes a circle centered at (x, y).
+	Circle(x, y, radius float64, color string, lineWidth float64)
+
+	// Path strokes lines through the points. The dash holds
+	// alternating dash and gap lengths, nil for a solid line.
+	// A closed path returns to its first point.
+	Path(points [][2]float64, color string, lineWidth float64, dash []float64, closed bool)
+
+	// Text writes text with its top left corner at (x, y).
+	Text(x, y float64, text string, color string, size float64)
+}
+
+// Scene is what to draw and how.
+type Scene struct {
+	Depots    entities.Depots
+	Customers entities.Customers
+	Agent     *solver.Agent
+
+	// View is the part of the problem to show.
+	// All depots and customers are shown if it is nil.
+	View *View
+
+	// Highlight is a route of the agent to highlight.
+	// Other routes are dimmed while one is highlighted.
+	Highlight *solver.Route
+
+	// Stats, if set, is used to find routes violating the load
+	// or duration limits. Those routes are drawn dashed.
+	Stats func(route *solver.Route) solver.RouteStats
+
+	// DepotLabels and CustomerLabels show the IDs of
+	// depots and customers next to them.
+	DepotLabels    bool
+	CustomerLabels bool
+}
+
+// Draw draws the depots, customers and the agent's
+// routes, each route in its own color.
+func Draw(p Painter, depots entities.Depots, customers entities.Customers, agent *solver.Agent) {
+	Scene{Depots: depots, Customers: customers, Agent: agent}.Draw(p)
+}
+
+// Draw draws the scene.
+func (sc Scene) Draw(p Painter) {
+	w, h := p.Size()
+	v := NewView(sc.Depots, sc.Customers, w, h)
+	if sc.View != nil {
+		v = *sc.View
+	}
+
+	p.Fill("#15202e")
+
+	// Draw customers
+	for id, customer := range sc.Customers {
+		x, y := v.Position(customer.GetPosition())
+		p.Circle(x, y, 1, "#FFF5", 2)
+		if sc.CustomerLabels {
+			p.Text(x+4, y+2, fmt.Sprint(id), "#FFF8", 10)
+		}
+	}
+
+	// Draw depots
+	for id, depot := range sc.Depots {
+		x, y := v.Position(depot.GetPosition())
+		p.Circle(x, y, 1, "#FFF", 4)
+		if sc.DepotLabels {
+			p.Text(x+6, y+4, fmt.Sprint(id), "#FFF", 12)
+		}
+	}
+
+	if sc.Agent == nil {
+		return
+	}
+
+	// Draw agents
+	for routeID, route := range sc.Agent.Dna {
+		color, err := scalarToColor(float64(routeID) / float64(len(sc.Agent.Dna)))
+		if err != nil {
+			panic(err)
+		}
+
+		stroke, lineWidth := color.String(), 1.0
+		var dash []float64
+		if sc.Stats != nil && !sc.Stats(route).IsFeasible() {
+			lineWidth, dash = 2, []float64{6, 4}
+		}
+		if sc.Highlight == route {
+			lineWidth = 3
+		} else if sc.Highlight != nil {
+			rgb := color.ToRGB()
+			stroke = fmt.Sprintf("#%02x%02x%02x40", rgb.R, rgb.G, rgb.B)
+		}
+
+		depot := sc.Depots[route.DepotID]
+		points := [][2]float64{}
+		x, y := v.Position(depot.GetPosition())
+		points = append(points, [2]float64{x, y})
+		for _, cID := range route.Path {
+			x, y := v.Position(sc.Customers[cID].GetPosition())
+			points = append(points, [2]float64{x, y})
+		}
+		p.Path(points, stroke, lineWidth, dash, !depot.OpenRoutes)
+	}
+}
+
+// Tooltip writes lines of text in a box next to (x, y),
+// kept within the surface.
+func Tooltip(p Painter, x, y float64, lines ...string) {
+	const size = 12
+	w, h := p.Size()
+	width, height := panelSize(size, lines)
+
+	x, y = x+12, y+12
+	if x+width > w {
+		x = w - width
+	}
+	if y+height > h {
+		y = h - height
+	}
+	panel(p, x, y, size, lines)
+}
+
+// Caption writes lines of text in the top left corner,
+// e.g. the generation number and fitness.
+func Caption(p Painter, lines ...string) {
+	panel(p, 10, 10, 14, lines)
+}
+
+// Sparkline draws the values as a line in a box with its top
+// left corner at (x, y), scaled to fill the box.
+func Sparkline(p Painter, x, y, w, h float64, values []float64, color string) {
+	p.Rect(x, y, w, h, "#000A")
+	if len(values) < 2 {
+		return
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	if max == min {
+		max = min + 1
+	}
+
+	const padding = 5
+	points := [][2]float64{}
+	for i, v := range values {
+		points = append(points, [2]float64{
+			x + padding + float64(i)/float64(len(values)-1)*(w-2*padding),
+			y + padding + (max-v)/(max-min)*(h-2*padding),
+		})
+	}
+	p.Path(points, color, 1, nil, false)
+}
+
+// panel writes lines of text in a box with
+// its top left corner at (x, y).
+func panel(p Painter, x, y, size float64, lines []string) {
+	width, height := panelSize(size, lines)
+	p.Rect(x, y, width, height, "#000A")
+	for i, line := range lines {
+		p.Text(x+6, y+5+float64(i)*size*1.4, line, "#FFF", size)
+	}
+}
+
+// panelSize returns the size of a panel with the lines of text.
+func panelSize(size float64, lines []string) (w, h float64) {
+	// The font is monospaced, about 0.6 em per character.
+	for _, line := range lines {
+		if lw := float64(len(line)) * size * 0.6; lw > w {
+			w = lw
+		}
+	}
+	return w + 12, float64(len(lines))*size*1.4 + 10
+}
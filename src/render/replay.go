@@ -0,0 +1,174 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tfriedel6/canvas"
+	"github.com/tfriedel6/canvas/backend/softwarebackend"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// Replay records the best agent every few generations
+// and replays the recording as an animated GIF. It is
+// subscribed to the solver as an observer. Long runs
+// are recorded more sparsely (see maxFrames).
+type Replay struct {
+	solver.BaseObserver
+
+	every  int
+	frames []frame
+
+	mu sync.Mutex
+}
+
+// maxFrames is the number of frames kept. Once more are recorded,
+// every other frame is dropped and frames are recorded half as
+// often, so that the replay still spans the whole run.
+const maxFrames = 300
+
+// gifPalette is the web-safe palette with the exact background
+// color added, so that the background is not dithered.
+var gifPalette = append(color.Palette{color.RGBA{0x15, 0x20, 0x2e, 0xff}}, palette.WebSafe...)
+
+// frame is a recorded generation.
+type frame struct {
+	generation int
+	agent      *solver.Agent
+}
+
+// NewReplay creates a replay recording every n generations.
+func NewReplay(every int) *Replay {
+	if every < 1 {
+		every = 1
+	}
+	return &Replay{every: every}
+}
+
+// OnGeneration records the generation.
+func (r *Replay) OnGeneration(info solver.GenerationInfo) {
+	r.Record(info)
+}
+
+// OnFinish records the final generation.
+func (r *Replay) OnFinish(info solver.GenerationInfo) {
+	r.RecordFinal(info)
+}
+
+// Record records a copy of the best agent if
+// the generation is one to be recorded.
+func (r *Replay) Record(info solver.GenerationInfo) {
+	r.mu.Lock()
+	every := r.every
+	r.mu.Unlock()
+
+	if info.GenerationNumber%every != 0 {
+		return
+	}
+	r.add(info)
+}
+
+// RecordFinal records the final generation unless it already is the
+// last recorded frame, so that the replay ends with the result.
+func (r *Replay) RecordFinal(info solver.GenerationInfo) {
+	r.mu.Lock()
+	recorded := len(r.frames) > 0 && r.frames[len(r.frames)-1].generation == info.GenerationNumber
+	r.mu.Unlock()
+
+	if !recorded {
+		r.add(info)
+	}
+}
+
+// add records a copy of the generation's best agent,
+// if the generation has one.
+func (r *Replay) add(info solver.GenerationInfo) {
+	if info.BestAgent == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.frames = append(r.frames, frame{generation: info.GenerationNumber, agent: info.BestAgent.Copy()})
+	if len(r.frames) > maxFrames {
+		thinned := []frame{}
+		for i, f := range r.frames {
+			if i%2 == 0 || i == len(r.frames)-1 {
+				thinned = append(thinned, f)
+			}
+		}
+		r.frames = thinned
+		r.every *= 2
+	}
+	r.mu.Unlock()
+}
+
+// Len returns the number of recorded frames.
+func (r *Replay) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.frames)
+}
+
+// WriteGIF draws every recorded frame with the generation number and
+// fitness as a caption, and writes them as an animated GIF. Each frame
+// is shown for delay, and the last frame is held for a second longer.
+func (r *Replay) WriteGIF(w io.Writer, width, height int, delay time.Duration, depots entities.Depots, customers entities.Customers) error {
+	r.mu.Lock()
+	frames := append([]frame{}, r.frames...)
+	r.mu.Unlock()
+
+	if len(frames) == 0 {
+		return fmt.Errorf("No frames recorded")
+	}
+
+	backend := softwarebackend.New(width, height)
+	cv := CanvasPainter{canvas.New(backend)}
+
+	animation := &gif.GIF{}
+	for i, f := range frames {
+		Draw(cv, depots, customers, f.agent)
+		Caption(cv,
+			fmt.Sprintf("Generation %d", f.generation),
+			fmt.Sprintf("Distance   %.2f", f.agent.Fitness.Distance),
+			fmt.Sprintf("Fitness    %.2f", f.agent.Fitness.Total),
+		)
+
+		// GIFs are paletted, so every frame is mapped to a fixed palette.
+		img := image.NewPaletted(backend.Image.Bounds(), gifPalette)
+		draw.Draw(img, img.Bounds(), backend.Image, image.Point{}, draw.Src)
+
+		frameDelay := int(delay / (10 * time.Millisecond))
+		if i == len(frames)-1 {
+			frameDelay += 100
+		}
+
+		animation.Image = append(animation.Image, img)
+		animation.Delay = append(animation.Delay, frameDelay)
+	}
+
+	return gif.EncodeAll(w, animation)
+}
+
+// WriteGIFFile writes the replay as an animated GIF file.
+func (r *Replay) WriteGIFFile(filePath string, width, height int, delay time.Duration, depots entities.Depots, customers entities.Customers) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := r.WriteGIF(file, width, height, delay, depots, customers); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+func TestReplayThinsLongRuns(t *testing.T) {
+	r := NewReplay(2)
+	last := 100000
+	for generation := 0; generation < last; generation++ {
+		r.OnGeneration(solver.GenerationInfo{GenerationNumber: generation, BestAgent: &solver.Agent{}})
+	}
+	r.OnFinish(solver.GenerationInfo{GenerationNumber: last, BestAgent: &solver.Agent{}})
+
+	if r.Len() > maxFrames+1 {
+		t.Fatalf("Expected at most %d frames, got %d", maxFrames+1, r.Len())
+	}
+	if r.Len() < maxFrames/2 {
+		t.Fatalf("Expected at least %d frames, got %d", maxFrames/2, r.Len())
+	}
+
+	// Frames are evenly spaced over the whole run.
+	frames := r.frames
+	if frames[0].generation != 0 || frames[len(frames)-1].generation != last {
+		t.Errorf("Expected frames from 0 to %d, got %d to %d", last, frames[0].generation, frames[len(frames)-1].generation)
+	}
+	for i := 1; i < len(frames)-1; i++ {
+		if step := frames[i].generation - frames[i-1].generation; step != r.every {
+			t.Fatalf("Expected frame %d %d generations after the previous, got %d", i, r.every, step)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// svgPainter paints SVG elements. The first write error
+// is kept and stops all further painting.
+type svgPainter struct {
+	w    io.Writer
+	size [2]float64
+	err  error
+}
+
+// printf writes to the SVG unless a previous write failed.
+func (sp *svgPainter) printf(format string, args ...interface{}) {
+	if sp.err != nil {
+		return
+	}
+	_, sp.err = fmt.Fprintf(sp.w, format, args...)
+}
+
+// Size returns the width and height of the SVG.
+func (sp *svgPainter) Size() (w, h float64) {
+	return sp.size[0], sp.size[1]
+}
+
+// Fill fills the whole SVG with the color.
+func (sp *svgPainter) Fill(color string) {
+	paint, opacity := svgPaint(color)
+	sp.printf("<rect width=\"100%%\" height=\"100%%\" fill=\"%s\" fill-opacity=\"%s\"/>\n", paint, opacity)
+}
+
+// Rect fills a rectangle with its top left corner at (x, y).
+func (sp *svgPainter) Rect(x, y, w, h float64, color string) {
+	paint, opacity := svgPaint(color)
+	sp.printf("<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\" fill-opacity=\"%s\"/>\n",
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), paint, opacity)
+}
+
+// Circle strokes a circle centered at (x, y).
+func (sp *svgPainter) Circle(x, y, radius float64, color string, lineWidth float64) {
+	paint, opacity := svgPaint(color)
+	sp.printf("<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"none\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"%s\"/>\n",
+		svgNumber(x), svgNumber(y), svgNumber(radius), paint, opacity, svgNumber(lineWidth))
+}
+
+// Path strokes lines through the points.
+func (sp *svgPainter) Path(points [][2]float64, color string, lineWidth float64, dash []float64, closed bool) {
+	if len(points) == 0 {
+		return
+	}
+
+	element := "polyline"
+	if closed {
+		element = "polygon"
+	}
+	coordinates := []string{}
+	for _, point := range points {
+		coordinates = append(coordinates, svgNumber(point[0])+","+svgNumber(point[1]))
+	}
+
+	dashArray := ""
+	if len(dash) > 0 {
+		lengths := []string{}
+		for _, length := range dash {
+			lengths = append(lengths, svgNumber(length))
+		}
+		dashArray = fmt.Sprintf(" stroke-dasharray=\"%s\"", strings.Join(lengths, " "))
+	}
+
+	paint, opacity := svgPaint(color)
+	sp.printf("<%s points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"%s\"%s/>\n",
+		element, strings.Join(coordinates, " "), paint, opacity, svgNumber(lineWidth), dashArray)
+}
+
+// Text writes text with its top left corner at (x, y).
+func (sp *svgPainter) Text(x, y float64, text string, color string, size float64) {
+	escaped := &strings.Builder{}
+	xml.EscapeText(escaped, []byte(text))
+
+	paint, opacity := svgPaint(color)
+	sp.printf("<text x=\"%s\" y=\"%s\" fill=\"%s\" fill-opacity=\"%s\" font-family=\"monospace\" font-size=\"%s\" dominant-baseline=\"hanging\">%s</text>\n",
+		svgNumber(x), svgNumber(y), paint, opacity, svgNumber(size), escaped)
+}
+
+// svgNumber formats a number for SVG attributes.
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// svgPaint splits canvas colors with an alpha channel
+// (#RGBA and #RRGGBBAA) into an SVG paint and opacity.
+// Other colors are passed through as fully opaque.
+func svgPaint(color string) (paint, opacity string) {
+	if !strings.HasPrefix(color, "#") || (len(color) != 5 && len(color) != 9) {
+		return color, "1"
+	}
+
+	hex := color[1:]
+	alpha := hex[len(hex)/4*3:]
+	if len(alpha) == 1 {
+		alpha += alpha
+	}
+	a, err := strconv.ParseUint(alpha, 16, 8)
+	if err != nil {
+		return color, "1"
+	}
+
+	return "#" + hex[:len(hex)/4*3], strconv.FormatFloat(float64(a)/255, 'f', 3, 64)
+}
@@ -0,0 +1,26 @@
+package render
+
+import "testing"
+
+func TestSVGPaint(t *testing.T) {
+	tests := []struct {
+		color, paint, opacity string
+	}{
+		{"#FFF3", "#FFF", "0.200"},
+		{"#4CF0", "#4CF", "0.000"},
+		{"#112233CC", "#112233", "0.800"},
+		{"#11223380", "#112233", "0.502"},
+		{"#4CF", "#4CF", "1"},
+		{"#112233", "#112233", "1"},
+		{"white", "white", "1"},
+		{"#FFFG", "#FFFG", "1"},
+		{"#FFFFFFZZ", "#FFFFFFZZ", "1"},
+	}
+
+	for _, test := range tests {
+		paint, opacity := svgPaint(test.color)
+		if paint != test.paint || opacity != test.opacity {
+			t.Errorf("%s: expected %s %s, got %s %s", test.color, test.paint, test.opacity, paint, opacity)
+		}
+	}
+}
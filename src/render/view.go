@@ -0,0 +1,157 @@
+package render
+
+import (
+	"math"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// padding is the space (px) kept free around the locations.
+const padding = 20
+
+// View maps problem coordinates to surface coordinates.
+// The aspect ratio of the problem is preserved, and the
+// view can be zoomed, panned and resized.
+type View struct {
+	// bounding box for nodes
+	// large X = max x, small x = min x etc...
+	X, x, Y, y float64
+
+	// FlipY flips the view upside down, so that
+	// y grows upwards as in the problem data.
+	FlipY bool
+
+	w, h float64
+
+	// zoom and offset are applied after fitting
+	// the bounding box to the surface.
+	zoom   float64
+	offset [2]float64
+}
+
+// NewView creates a view of all depots and customers
+// on a surface of the provided size.
+func NewView(depots entities.Depots, customers entities.Customers, w, h float64) View {
+	locations := []entities.Location{}
+	for _, item := range customers {
+		locations = append(locations, item)
+	}
+	for _, item := range depots {
+		locations = append(locations, item)
+	}
+
+	X, x, Y, y := getBoundary(locations)
+
+	return View{
+		X:    X,
+		x:    x,
+		Y:    Y,
+		y:    y,
+		w:    w,
+		h:    h,
+		zoom: 1,
+	}
+}
+
+// fit returns the scale and surface origin that fit
+// the bounding box centered on the surface.
+func (v View) fit() (scale, ox, oy float64) {
+	dx, dy := v.X-v.x, v.Y-v.y
+
+	scale = math.Min((v.w-2*padding)/dx, (v.h-2*padding)/dy)
+	if math.IsInf(scale, 0) || scale <= 0 {
+		// All locations are at the same point.
+		scale = 1
+	}
+
+	return scale, (v.w - dx*scale) / 2, (v.h - dy*scale) / 2
+}
+
+// Position returns the surface position of a problem position.
+func (v View) Position(_x, _y float64) (float64, float64) {
+	scale, ox, oy := v.fit()
+
+	sx := ox + (_x-v.x)*scale
+	sy := oy + (_y-v.y)*scale
+	if v.FlipY {
+		sy = oy + (v.Y-_y)*scale
+	}
+
+	return sx*v.zoom + v.offset[0], sy*v.zoom + v.offset[1]
+}
+
+// Location returns the problem position of a surface position.
+func (v View) Location(sx, sy float64) (float64, float64) {
+	scale, ox, oy := v.fit()
+
+	sx = (sx - v.offset[0]) / v.zoom
+	sy = (sy - v.offset[1]) / v.zoom
+
+	_x := v.x + (sx-ox)/scale
+	_y := v.y + (sy-oy)/scale
+	if v.FlipY {
+		_y = v.Y - (sy-oy)/scale
+	}
+
+	return _x, _y
+}
+
+// Size returns the size of the surface.
+func (v View) Size() (w, h float64) {
+	return v.w, v.h
+}
+
+// Zoom zooms the view by the factor, keeping the
+// surface position (sx, sy) in place.
+func (v View) Zoom(factor, sx, sy float64) View {
+	v.zoom *= factor
+	v.offset[0] = sx - (sx-v.offset[0])*factor
+	v.offset[1] = sy - (sy-v.offset[1])*factor
+	return v
+}
+
+// Pan moves the view by (dx, dy) on the surface.
+func (v View) Pan(dx, dy float64) View {
+	v.offset[0] += dx
+	v.offset[1] += dy
+	return v
+}
+
+// Resize fits the view to a surface of a new size. The zoom
+// is kept, and so is the problem position at the center.
+func (v View) Resize(w, h float64) View {
+	cx, cy := v.Location(v.w/2, v.h/2)
+
+	v.w, v.h = w, h
+	v.offset = [2]float64{}
+	sx, sy := v.Position(cx, cy)
+
+	return v.Pan(w/2-sx, h/2-sy)
+}
+
+// Flip flips the view upside down, keeping the
+// problem position at the center.
+func (v View) Flip() View {
+	cx, cy := v.Location(v.w/2, v.h/2)
+
+	v.FlipY = !v.FlipY
+	sx, sy := v.Position(cx, cy)
+
+	return v.Pan(v.w/2-sx, v.h/2-sy)
+}
+
+// getBoundary returns the bounding box of the locations.
+func getBoundary(locations []entities.Location) (X float64, x float64, Y float64, y float64) {
+	X, Y = locations[0].GetPosition()
+	x, y = X, Y
+
+	for _, location := range locations {
+		_x, _y := location.GetPosition()
+		X = math.Max(X, _x)
+		x = math.Min(x, _x)
+		Y = math.Max(Y, _y)
+		y = math.Min(y, _y)
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// newTestView creates a view of the box (0, 0)-(100, 50) on
+// a 240x140 surface, which fits it at a scale of 2 at (20, 20).
+func newTestView() View {
+	depots := entities.Depots{0: {X: 0, Y: 0}}
+	customers := entities.Customers{1: {X: 100, Y: 50}}
+	return NewView(depots, customers, 240, 140)
+}
+
+func TestViewPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		view   View
+		x, y   float64
+		sx, sy float64
+	}{
+		{"fitted min", newTestView(), 0, 0, 20, 20},
+		{"fitted max", newTestView(), 100, 50, 220, 120},
+		{"fitted center", newTestView(), 50, 25, 120, 70},
+		{"flipped min", newTestView().Flip(), 0, 0, 20, 120},
+		{"flipped max", newTestView().Flip(), 100, 50, 220, 20},
+		{"zoomed at min", newTestView().Zoom(2, 20, 20), 0, 0, 20, 20},
+		{"zoomed at min, max", newTestView().Zoom(2, 20, 20), 100, 50, 420, 220},
+		{"zoomed twice", newTestView().Zoom(2, 20, 20).Zoom(0.5, 20, 20), 100, 50, 220, 120},
+		{"panned", newTestView().Pan(5, -5), 0, 0, 25, 15},
+		{"panned twice", newTestView().Pan(5, -5).Pan(-5, 5), 0, 0, 20, 20},
+		{"flipped twice", newTestView().Flip().Flip(), 0, 0, 20, 20},
+		// Fits at a scale of 4.4 at (20, 30).
+		{"resized min", newTestView().Resize(480, 280), 0, 0, 20, 30},
+		{"resized center", newTestView().Resize(480, 280), 50, 25, 240, 140},
+		{"zoomed then resized center", newTestView().Zoom(2, 0, 0).Resize(480, 280), 20, 7.5, 240, 140},
+		{"panned then resized center", newTestView().Pan(40, 0).Resize(480, 280), 30, 25, 240, 140},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sx, sy := test.view.Position(test.x, test.y)
+			if !near(sx, test.sx) || !near(sy, test.sy) {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", test.sx, test.sy, sx, sy)
+			}
+
+			x, y := test.view.Location(sx, sy)
+			if !near(x, test.x) || !near(y, test.y) {
+				t.Errorf("Expected location (%v, %v), got (%v, %v)", test.x, test.y, x, y)
+			}
+		})
+	}
+}
+
+func TestViewSize(t *testing.T) {
+	w, h := newTestView().Zoom(3, 10, 10).Resize(300, 200).Size()
+	if w != 300 || h != 200 {
+		t.Errorf("Expected 300x200, got %vx%v", w, h)
+	}
+}
+
+func TestViewSinglePoint(t *testing.T) {
+	view := NewView(entities.Depots{0: {X: 7, Y: 7}}, entities.Customers{}, 240, 140)
+
+	sx, sy := view.Position(7, 7)
+	if !near(sx, 120) || !near(sy, 70) {
+		t.Errorf("Expected the surface center, got (%v, %v)", sx, sy)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
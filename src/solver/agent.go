@@ -15,67 +15,82 @@ import (
 type Agent struct {
 	Dna     DNA
 	Fitness Fitness
+
+	// Operators are the operators that produced the agent,
+	// in the order they were applied.
+	Operators []Operator
 }
 
+// Operator is an operator producing or changing agents.
+type Operator string
+
+const (
+	RandomInitialization Operator = "random initialization"
+	SeededInitialization Operator = "seeded initialization"
+	Crossover            Operator = "crossover"
+	RouteSplit           Operator = "route split"
+	DepotRelocation      Operator = "depot relocation"
+	BorderlineRelocation Operator = "borderline relocation"
+	VehicleSwap          Operator = "vehicle swap"
+)
+
 // NewAgent creates a new random agent and evaluates the agent.
-func NewAgent(s *Solver) *Agent {
+func NewAgent(s *Solver, rng *rand.Rand) *Agent {
 	agent := &Agent{
-		Dna: NewDNA(s.Depots, s.Customers),
+		Dna:       NewDNA(s.Depots, s.Customers, s.grouping, rng),
+		Operators: []Operator{RandomInitialization},
 	}
 
-	agent.Evaluate(s.Depots, s.Customers)
+	agent.Evaluate(s)
 
 	return agent
 }
 
 // Evaluate evaluates the fitness of the agent.
 // The fitness is stored in the agent as a property.
-func (agent *Agent) Evaluate(depots entities.Depots, customers entities.Customers) {
+func (agent *Agent) Evaluate(s *Solver) {
 	agent.Fitness.Clear()
-
 	for _, route := range agent.Dna {
-		if len(route.Path) == 0 {
-			continue
-		}
-
-		// Add depot -> c_1 and depot -> c_n
-		agent.Fitness.Distance += distance(depots[route.DepotID], customers[route.Path[0]])
-		agent.Fitness.Distance += distance(depots[route.DepotID], customers[route.Path[len(route.Path)-1]])
-
-		// Add c_1 -> c_2, c_2 -> c_3, ... , c_n-1 -> c_n.
-		for i := 0; i < len(route.Path)-1; i++ {
-			agent.Fitness.Distance += distance(customers[route.Path[i]], customers[route.Path[i+1]])
-		}
-
-		// Accumulate all demand for the route.
-		demand := 0.0
-		for _, cID := range route.Path {
-			demand += customers[cID].Demand
-		}
-
-		// Add the positive difference between the max load and demand.
-		// If there is no positive difference the over-demand is 0.
-		agent.Fitness.OverDemand += math.Max(demand-depots[route.DepotID].MaxVehicleLoad, 0)
+		fitness := s.evaluateRoute(route)
+		agent.Fitness.Add(&fitness)
 	}
-
 	agent.Fitness.CalculateTotal()
 }
 
+// evaluateRoute evaluates the fitness of a single route.
+// The total is left out, as it depends on all routes.
+// Its violations are those of the route's stats.
+func (s *Solver) evaluateRoute(route *Route) Fitness {
+	stats := s.RouteStats(route)
+	return Fitness{
+		Distance:            stats.Distance,
+		Cost:                stats.Cost,
+		OverDemand:          stats.OverLoad,
+		TimeWindowViolation: stats.Lateness,
+		OverDuration:        stats.OverDuration,
+	}
+}
+
 func (a *Agent) Copy() (child *Agent) {
 	child = &Agent{
 		Fitness: Fitness{
-			Total:      a.Fitness.Total,
-			Distance:   a.Fitness.Distance,
-			OverDemand: a.Fitness.OverDemand,
+			Total:               a.Fitness.Total,
+			Distance:            a.Fitness.Distance,
+			Cost:                a.Fitness.Cost,
+			OverDemand:          a.Fitness.OverDemand,
+			TimeWindowViolation: a.Fitness.TimeWindowViolation,
+			OverDuration:        a.Fitness.OverDuration,
 		},
+		Operators: append([]Operator{}, a.Operators...),
 	}
 
 	for _, route := range a.Dna {
 		var copyPath []int
 		copier.Copy(&copyPath, &route.Path)
 		child.Dna = append(child.Dna, &Route{
-			DepotID: route.DepotID,
-			Path:    copyPath,
+			DepotID:     route.DepotID,
+			VehicleType: route.VehicleType,
+			Path:        copyPath,
 		})
 	}
 
@@ -85,16 +100,26 @@ func (a *Agent) Copy() (child *Agent) {
 // InjectRoute injects a route into its best placement.
 // The injected route is decomposed and fitted into the existing
 // routes so that the best overall per-new-customer is achieved.
-func (agent *Agent) InjectRoute(injectedRoute *Route, s *Solver) {
+// With time windows, insertions that do not add to the time
+// window violation are preferred over those that do.
+// An insertion only changes the fitness of its route, so only
+// that route is re-evaluated for each candidate insertion.
+func (agent *Agent) InjectRoute(injectedRoute *Route, s *Solver, rng *rand.Rand) {
 	agent.Dna.RemoveRouteNodes(injectedRoute)
 
+	routeFitness := make([]Fitness, len(agent.Dna))
+	for j, route := range agent.Dna {
+		routeFitness[j] = s.evaluateRoute(route)
+	}
+
 	for _, cID := range injectedRoute.Path {
 		bestScore := 999999999999.0
-		bestRoute := agent.Dna[0]
+		bestJ := 0
 		bestI := 0
+		bestFits := false
 
-		for _, route := range agent.Dna {
-			if route.DepotID != injectedRoute.DepotID && rand.Intn(s.RandomChanceEvaluateOuterDepotRoute) != 0 {
+		for j, route := range agent.Dna {
+			if route.DepotID != injectedRoute.DepotID && rng.Intn(s.RandomChanceEvaluateOuterDepotRoute) != 0 {
 				// In most cases, we do not bother checking routes that
 				// do not belong to the injected route's depot.
 				// i.e: each route is (often) closest to the depot it is connected to.
@@ -103,15 +128,28 @@ func (agent *Agent) InjectRoute(injectedRoute *Route, s *Solver) {
 				// Which is why we have a small chance of checking outer depot routes.
 				continue
 			}
+
+			// The fitness of the other routes.
+			others := Fitness{}
+			for k := range agent.Dna {
+				if k != j {
+					others.Add(&routeFitness[k])
+				}
+			}
+
 			for i := 0; i < len(route.Path); i++ {
 				route.Path = append(route.Path[:i+1], route.Path[i:]...)
 				route.Path[i] = cID
 
-				agent.Evaluate(s.Depots, s.Customers)
-				if agent.Fitness.Total < bestScore {
-					bestScore = agent.Fitness.Total
-					bestRoute = route
+				inserted := s.evaluateRoute(route)
+				fits := inserted.TimeWindowViolation <= routeFitness[j].TimeWindowViolation
+				fitness := others
+				fitness.Add(&inserted)
+				if (fits || !bestFits) && (fitness.Total < bestScore || (fits && !bestFits)) {
+					bestScore = fitness.Total
+					bestJ = j
 					bestI = i
+					bestFits = fits
 				}
 
 				// remove inserted point
@@ -120,17 +158,26 @@ func (agent *Agent) InjectRoute(injectedRoute *Route, s *Solver) {
 
 		}
 
+		bestRoute := agent.Dna[bestJ]
 		bestRoute.Path = append(bestRoute.Path[:bestI+1], bestRoute.Path[bestI:]...)
 		bestRoute.Path[bestI] = cID
+		routeFitness[bestJ] = s.evaluateRoute(bestRoute)
 	}
+
+	agent.Evaluate(s)
 }
 
 // RandomMutation runs a mutation procedure on the agent.
 // This allows chances for the following mutations:
 // - splitting a route in two
 // - re-locating a route's depot
-// all mutations follow constraints.
-func (agent *Agent) RandomMutation(s *Solver) {
+// - moving a borderline customer to another candidate depot
+// - giving a route another of its depot's vehicle types
+// all mutations follow constraints. The applied
+// mutations are added to the agent's operators.
+func (agent *Agent) RandomMutation(s *Solver, rng *rand.Rand) {
+	split, relocated := false, false
+
 	// FIXME: hardcoded chance
 	for _, route := range agent.Dna {
 		if len(route.Path) == 0 {
@@ -138,26 +185,30 @@ func (agent *Agent) RandomMutation(s *Solver) {
 		}
 
 		hasBeenSplit := false
-		if rand.Intn(s.RandomChanceRouteSplit) == 0 {
-			availableDepotID, err := agent.availableDepot(s, route.DepotID)
+		if rng.Intn(s.RandomChanceRouteSplit) == 0 {
+			availableDepotID, vehicleType, err := agent.availableDepot(s, route.DepotID)
 			if err != nil {
 				continue
 			}
 
+			// The halves are copied, as routes must not share a
+			// backing array when customers are inserted later.
 			splitPoint := len(route.Path) / 2
 			splitRoute := Route{
-				DepotID: availableDepotID,
-				Path:    route.Path[:splitPoint],
+				DepotID:     availableDepotID,
+				VehicleType: vehicleType,
+				Path:        append([]int{}, route.Path[:splitPoint]...),
 			}
-			route.Path = route.Path[splitPoint:]
+			route.Path = append([]int{}, route.Path[splitPoint:]...)
 			agent.Dna = append(agent.Dna, &splitRoute)
 
 			hasBeenSplit = true
+			split = true
 		}
 
 		// If we have split the path, we want to ensure that this path
 		// is connected to its closest depot (if available).
-		if hasBeenSplit || rand.Intn(s.RandomChanceDepotRelocation) == 1 {
+		if hasBeenSplit || rng.Intn(s.RandomChanceDepotRelocation) == 1 {
 			m := map[int]float64{}
 			for i, depot := range s.Depots {
 				if i == route.DepotID {
@@ -165,7 +216,7 @@ func (agent *Agent) RandomMutation(s *Solver) {
 				}
 				m[i] = 0
 				for _, cID := range route.Path {
-					m[i] += distance(depot, s.Customers[cID])
+					m[i] += s.distance(depot, s.Customers[cID])
 				}
 			}
 
@@ -173,12 +224,17 @@ func (agent *Agent) RandomMutation(s *Solver) {
 				lowestVal := 9999999999.0
 				lowestKey := 0
 				for k, v := range m {
-					if v < lowestVal {
+					// Ties go to the lowest depot ID, so the
+					// result does not depend on the map order.
+					if v < lowestVal || (v == lowestVal && k < lowestKey) {
+						lowestVal = v
 						lowestKey = k
 					}
 				}
-				if agent.depotIsAvailable(s, lowestKey) {
+				if vehicleType, ok := agent.availableVehicle(s, lowestKey); ok {
 					route.DepotID = lowestKey
+					route.VehicleType = vehicleType
+					relocated = true
 					break
 				}
 
@@ -186,40 +242,187 @@ func (agent *Agent) RandomMutation(s *Solver) {
 			}
 		}
 	}
+
+	if split {
+		agent.Operators = append(agent.Operators, RouteSplit)
+	}
+	if relocated {
+		agent.Operators = append(agent.Operators, DepotRelocation)
+	}
+
+	if rng.Intn(s.RandomChanceBorderlineRelocation) == 0 && agent.relocateBorderlineCustomer(s, rng) {
+		agent.Operators = append(agent.Operators, BorderlineRelocation)
+	}
+
+	if rng.Intn(s.RandomChanceVehicleSwap) == 0 && agent.swapVehicleType(s, rng) {
+		agent.Operators = append(agent.Operators, VehicleSwap)
+	}
 }
 
-// depotIsAvailable checks if the provided depot id
-// has available routes.
-func (agent *Agent) depotIsAvailable(s *Solver, id int) bool {
-	max := s.Depots[id].MaxNumVehicles
+// swapVehicleType gives a random route another of its depot's
+// vehicle types. If all vehicles of the type are in use, the
+// route swaps vehicle types with a route using one. It returns
+// true if the route's vehicle type was changed.
+func (agent *Agent) swapVehicleType(s *Solver, rng *rand.Rand) bool {
+	route := agent.Dna.GetRandomRoute(rng)
+	fleet := s.fleets[route.DepotID]
+	if len(route.Path) == 0 || len(fleet) < 2 {
+		return false
+	}
+
+	vehicleType := rng.Intn(len(fleet) - 1)
+	if vehicleType >= route.VehicleType {
+		vehicleType++
+	}
+
+	others := []*Route{}
+	for _, other := range agent.Dna {
+		if other.DepotID == route.DepotID && other.VehicleType == vehicleType {
+			others = append(others, other)
+		}
+	}
+	if len(others) >= fleet[vehicleType].Count {
+		if len(others) == 0 {
+			return false
+		}
+		others[rng.Intn(len(others))].VehicleType = route.VehicleType
+	}
 
-	numOccurences := 1 // if we add a new one
+	route.VehicleType = vehicleType
+	return true
+}
+
+// relocateBorderlineCustomer moves a random borderline customer
+// from its current depot to the cheapest insertion point in the
+// routes of one of its other candidate depots. It returns
+// true if a customer was moved.
+func (agent *Agent) relocateBorderlineCustomer(s *Solver, rng *rand.Rand) bool {
+	if len(s.borderline) == 0 {
+		return false
+	}
+
+	cID := s.borderline[rng.Intn(len(s.borderline))]
+	currentRoute, index := agent.Dna.FindCustomer(cID)
+	if currentRoute == nil {
+		return false
+	}
+
+	candidates := []int{}
+	for _, dID := range s.grouping[cID] {
+		if dID != currentRoute.DepotID {
+			candidates = append(candidates, dID)
+		}
+	}
+	depotID := candidates[rng.Intn(len(candidates))]
+	customer := s.Customers[cID]
+
+	var bestRoute *Route
+	bestI := 0
+	bestCost := math.Inf(1)
+	bestFits := false
 	for _, route := range agent.Dna {
-		if route.DepotID == id {
-			numOccurences++
-			if numOccurences > max {
-				return false
+		if route.DepotID != depotID {
+			continue
+		}
+
+		// Prefer routes that can take the customer's demand
+		// without exceeding the vehicle load.
+		load := 0.0
+		for _, _cID := range route.Path {
+			load += s.Customers[_cID].Demand
+		}
+		fits := load+customer.Demand <= s.vehicleType(route).MaxLoad
+		if bestFits && !fits {
+			continue
+		}
+
+		for i := 0; i <= len(route.Path); i++ {
+			cost := insertionCost(s, route, i, customer)
+			if cost < bestCost || (fits && !bestFits) {
+				bestRoute = route
+				bestI = i
+				bestCost = cost
+				bestFits = fits
 			}
 		}
 	}
 
+	if bestRoute == nil {
+		vehicleType, ok := agent.availableVehicle(s, depotID)
+		if !ok {
+			return false
+		}
+		bestRoute = &Route{DepotID: depotID, VehicleType: vehicleType}
+		agent.Dna = append(agent.Dna, bestRoute)
+	}
+
+	// Both paths are rebuilt rather than changed in place,
+	// so that no other route's path is overwritten.
+	path := make([]int, 0, len(currentRoute.Path)-1)
+	path = append(path, currentRoute.Path[:index]...)
+	currentRoute.Path = append(path, currentRoute.Path[index+1:]...)
+
+	path = make([]int, 0, len(bestRoute.Path)+1)
+	path = append(path, bestRoute.Path[:bestI]...)
+	path = append(path, cID)
+	bestRoute.Path = append(path, bestRoute.Path[bestI:]...)
 	return true
 }
 
+// insertionCost returns the added distance of inserting
+// the customer at index i of the route's path.
+func insertionCost(s *Solver, route *Route, i int, customer *entities.Customer) float64 {
+	depot := s.Depots[route.DepotID]
+	var prev, next entities.Location = depot, depot
+	if i > 0 {
+		prev = s.Customers[route.Path[i-1]]
+	}
+	if i < len(route.Path) {
+		next = s.Customers[route.Path[i]]
+	} else if depot.OpenRoutes {
+		// The customer becomes the end of the open route.
+		return s.distance(prev, customer)
+	}
+
+	return s.distance(prev, customer) + s.distance(customer, next) - s.distance(prev, next)
+}
+
+// availableVehicle returns a vehicle type of the provided
+// depot with vehicles not yet given a route, if any.
+func (agent *Agent) availableVehicle(s *Solver, id int) (int, bool) {
+	used := map[int]int{}
+	for _, route := range agent.Dna {
+		if route.DepotID == id {
+			used[route.VehicleType]++
+		}
+	}
+
+	for vehicleType, vehicle := range s.fleets[id] {
+		if used[vehicleType] < vehicle.Count {
+			return vehicleType, true
+		}
+	}
+
+	return 0, false
+}
+
 // availableDepot returns an arbitrary depot that can be given
-// more routes. If there is no available depots, an error will
-// be returned.
-func (agent *Agent) availableDepot(s *Solver, biasID int) (int, error) {
-	if agent.depotIsAvailable(s, biasID) {
-		return biasID, nil
+// more routes and the vehicle type available for the route. If
+// there is no available depots, an error will be returned.
+func (agent *Agent) availableDepot(s *Solver, biasID int) (int, int, error) {
+	if vehicleType, ok := agent.availableVehicle(s, biasID); ok {
+		return biasID, vehicleType, nil
 	}
-	for i := range s.Depots {
-		if i != biasID && agent.depotIsAvailable(s, i) {
-			return i, nil
+	for _, i := range s.Depots.IDs() {
+		if i == biasID {
+			continue
+		}
+		if vehicleType, ok := agent.availableVehicle(s, i); ok {
+			return i, vehicleType, nil
 		}
 	}
 
-	return 0, fmt.Errorf("No available depots")
+	return 0, 0, fmt.Errorf("No available depots")
 }
 
 // Agents is a collection of agents.
@@ -227,7 +430,7 @@ type Agents []*Agent
 
 // SelectOne selects an agent from the collection
 // using the provided selector as the selection method.
-func (agents Agents) SelectOne(method Selector) (int, *Agent) {
+func (agents Agents) SelectOne(method Selector, rng *rand.Rand) (int, *Agent) {
 	switch method {
 	case Roulette:
 		{
@@ -240,7 +443,7 @@ func (agents Agents) SelectOne(method Selector) (int, *Agent) {
 				sum += agent.Fitness.Total
 			}
 
-			value := rand.Float64() * sum
+			value := rng.Float64() * sum
 			for i, agent := range agents {
 				value -= (highest - agent.Fitness.Total)
 				if value <= 0 {
@@ -251,6 +454,6 @@ func (agents Agents) SelectOne(method Selector) (int, *Agent) {
 	case Random: // random by default
 	}
 
-	index := rand.Intn(len(agents))
+	index := rng.Intn(len(agents))
 	return index, agents[index]
 }
@@ -0,0 +1,217 @@
+package solver
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/problem"
+)
+
+// newTestConfig adds the problem in the problems directory to cfg.
+func newTestConfig(t *testing.T, name string, cfg SolverConfig) SolverConfig {
+	t.Helper()
+
+	instance, err := problem.LoadInstance("../../problems/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Depots, cfg.Customers = instance.Depots, instance.Customers
+	cfg.ProblemType, cfg.Metric = instance.Type, instance.Metric
+	return cfg
+}
+
+// newTestSolver creates a solver for the problem in the problems directory.
+func newTestSolver(t *testing.T, name string, cfg SolverConfig) *Solver {
+	t.Helper()
+
+	s, err := NewSolver(newTestConfig(t, name, cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRandomMutationKeepsDNAValid(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{
+		Seed:                             1,
+		BorderlineBound:                  0.5,
+		RandomChanceRouteSplit:           2,
+		RandomChanceDepotRelocation:      2,
+		RandomChanceBorderlineRelocation: 1,
+	})
+	rng := rand.New(rand.NewSource(s.Seed))
+
+	// One route per depot leaves vehicles free for splits.
+	routes := map[int]*Route{}
+	for cID := range s.Customers {
+		depotID := s.grouping[cID][0]
+		if routes[depotID] == nil {
+			routes[depotID] = &Route{DepotID: depotID}
+		}
+		routes[depotID].Path = append(routes[depotID].Path, cID)
+	}
+	agent := &Agent{}
+	for _, route := range routes {
+		agent.Dna = append(agent.Dna, route)
+	}
+
+	for i := 0; i < 2000; i++ {
+		agent.RandomMutation(s, rng)
+		if err := agent.Dna.Validate(s.Depots, s.Customers); err != nil {
+			t.Fatalf("Mutation %d: %v", i, err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// The route 0 -> 1 -> 2 -> 0 travels 5 + 5 + 10.
+	newDepot := func() *entities.Depot {
+		return &entities.Depot{MaxNumVehicles: 1, MaxVehicleLoad: 100}
+	}
+	customers := func() entities.Customers {
+		return entities.Customers{
+			1: {ID: 1, X: 3, Y: 4, Demand: 10},
+			2: {ID: 2, X: 6, Y: 8, Demand: 20},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		depot     func(d *entities.Depot)
+		customers func(cs entities.Customers)
+		route     Route
+		want      Fitness
+	}{
+		{"closed", nil, nil, Route{}, Fitness{Distance: 20, Cost: 20, Total: 20}},
+		{"open", func(d *entities.Depot) { d.OpenRoutes = true }, nil, Route{}, Fitness{Distance: 10, Cost: 10, Total: 10}},
+		{"over-demand", func(d *entities.Depot) { d.MaxVehicleLoad = 25 }, nil, Route{},
+			Fitness{Distance: 20, Cost: 20, OverDemand: 5, Total: 20 + 100*25}},
+		{"over-duration", func(d *entities.Depot) { d.MaxRouteDuration = 15 }, nil, Route{},
+			Fitness{Distance: 20, Cost: 20, OverDuration: 5, Total: 20 + 100*25}},
+		{"fleet", func(d *entities.Depot) {
+			d.Fleet = []entities.VehicleType{
+				{Count: 1, MaxLoad: 100, DistanceCost: 1},
+				{Count: 1, MaxLoad: 20, FixedCost: 7, DistanceCost: 2},
+			}
+		}, nil, Route{VehicleType: 1}, Fitness{Distance: 20, Cost: 7 + 2*20, OverDemand: 10, Total: 47 + 100*100}},
+		// Service at 1 starts 2 late, the vehicle waits 10 for
+		// 2 to open, and returns at 30, 5 after the depot closes.
+		{"time windows", func(d *entities.Depot) {
+			d.OpeningHours = &entities.TimeWindow{Start: 0, End: 25}
+		}, func(cs entities.Customers) {
+			cs[1].TimeWindow = &entities.TimeWindow{Start: 0, End: 3}
+			cs[2].TimeWindow = &entities.TimeWindow{Start: 20, End: 30}
+		}, Route{}, Fitness{Distance: 20, Cost: 20, TimeWindowViolation: 7, Total: 20 + 100*49}},
+		{"time windows, open", func(d *entities.Depot) {
+			d.OpeningHours = &entities.TimeWindow{Start: 0, End: 25}
+			d.OpenRoutes = true
+		}, func(cs entities.Customers) {
+			cs[1].TimeWindow = &entities.TimeWindow{Start: 0, End: 3}
+		}, Route{}, Fitness{Distance: 10, Cost: 10, TimeWindowViolation: 2, Total: 10 + 100*4}},
+		// Departing at 5 reaches 1 as it opens.
+		{"time windows, late departure", nil, func(cs entities.Customers) {
+			cs[1].TimeWindow = &entities.TimeWindow{Start: 10, End: 10}
+		}, Route{}, Fitness{Distance: 20, Cost: 20, Total: 20}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			depot, cs := newDepot(), customers()
+			if test.depot != nil {
+				test.depot(depot)
+			}
+			if test.customers != nil {
+				test.customers(cs)
+			}
+			s, err := NewSolver(SolverConfig{Depots: entities.Depots{0: depot}, Customers: cs, ProblemType: entities.VRP})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			route := test.route
+			route.Path = []int{1, 2}
+			agent := &Agent{Dna: DNA{&route, {DepotID: 0}}}
+			agent.Evaluate(s)
+
+			if agent.Fitness != test.want {
+				t.Errorf("Expected %v, got %v", test.want, agent.Fitness)
+			}
+		})
+	}
+}
+
+func TestInjectRouteMatchesFullEvaluation(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{Seed: 1})
+	// Time windows around the customers' IDs leave most agents late.
+	for _, customer := range s.Customers {
+		start := float64(customer.ID * 10)
+		customer.TimeWindow = &entities.TimeWindow{Start: start, End: start + 40}
+	}
+	s.timeWindows = true
+
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		a, b := NewAgent(s, rng), NewAgent(s, rng)
+		route := b.Dna.GetRandomRoute(rng)
+
+		got, want := a.Copy(), a.Copy()
+		got.InjectRoute(route, s, rand.New(rand.NewSource(int64(n))))
+		injectRouteByFullEvaluation(want, route, s)
+
+		if !reflect.DeepEqual(got.Dna, want.Dna) {
+			t.Fatalf("Injection %d: expected\n%v\ngot\n%v", n, want.Dna, got.Dna)
+		}
+		if got.Fitness != want.Fitness {
+			t.Fatalf("Injection %d: expected %v, got %v", n, want.Fitness, got.Fitness)
+		}
+	}
+}
+
+// injectRouteByFullEvaluation injects the route like InjectRoute,
+// but evaluates the whole agent for every candidate insertion.
+// Routes of other depots are never considered.
+func injectRouteByFullEvaluation(agent *Agent, injectedRoute *Route, s *Solver) {
+	agent.Dna.RemoveRouteNodes(injectedRoute)
+
+	for _, cID := range injectedRoute.Path {
+		bestScore := 999999999999.0
+		bestRoute := agent.Dna[0]
+		bestI := 0
+		bestFits := false
+
+		agent.Evaluate(s)
+		violation := agent.Fitness.TimeWindowViolation
+
+		for _, route := range agent.Dna {
+			if route.DepotID != injectedRoute.DepotID {
+				continue
+			}
+			for i := 0; i < len(route.Path); i++ {
+				path := append([]int{}, route.Path[:i]...)
+				path = append(path, cID)
+				path = append(path, route.Path[i:]...)
+				original := route.Path
+				route.Path = path
+
+				agent.Evaluate(s)
+				fits := agent.Fitness.TimeWindowViolation <= violation
+				if (fits || !bestFits) && (agent.Fitness.Total < bestScore || (fits && !bestFits)) {
+					bestScore = agent.Fitness.Total
+					bestRoute = route
+					bestI = i
+					bestFits = fits
+				}
+
+				route.Path = original
+			}
+		}
+
+		path := append([]int{}, bestRoute.Path[:bestI]...)
+		path = append(path, cID)
+		bestRoute.Path = append(path, bestRoute.Path[bestI:]...)
+	}
+
+	agent.Evaluate(s)
+}
@@ -0,0 +1,166 @@
+package solver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint is a snapshot of the solver's state which
+// a solver can be restored from.
+type Checkpoint struct {
+	// Generation is the last completed generation.
+	Generation int
+
+	// Seed is the solver's seed. The random number generators
+	// are derived from it and the generation number.
+	Seed int64
+
+	// Elapsed is how long the solver had run.
+	Elapsed time.Duration
+
+	// Operators are the operators that produced each agent in
+	// the population, and Best is the best agent so far.
+	Population []DNA
+	Operators  [][]Operator
+	Best       *Agent
+
+	Parameters Parameters
+}
+
+// Parameters are the solver parameters that may change
+// during a run and are therefore part of a checkpoint.
+type Parameters struct {
+	SelectionSize   float64
+	SelectionMethod Selector
+
+	RandomChanceRouteSplit              int
+	RandomChanceDepotRelocation         int
+	RandomChanceEvaluateOuterDepotRoute int
+	RandomChanceBorderlineRelocation    int
+	RandomChanceVehicleSwap             int
+}
+
+// SaveCheckpoint writes the checkpoint to the specified filePath.
+// The checkpoint is written to a temporary file first, so that an
+// interrupted write never corrupts an existing checkpoint.
+func SaveCheckpoint(filePath string, cp *Checkpoint) error {
+	file, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := json.NewEncoder(file).Encode(cp); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filePath)
+}
+
+// LoadCheckpoint reads a checkpoint written by SaveCheckpoint.
+func LoadCheckpoint(filePath string) (*Checkpoint, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cp := &Checkpoint{}
+	if err := json.NewDecoder(file).Decode(cp); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// RestoreSolver creates a new solver that continues from the
+// checkpoint. The checkpoint's population and parameters take
+// precedence over the provided configuration.
+func RestoreSolver(cfg SolverConfig, cp *Checkpoint) (*Solver, error) {
+	cfg.InitialDNA = nil
+	s, err := NewSolver(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, dna := range cp.Population {
+		if err := dna.Validate(s.Depots, s.Customers); err != nil {
+			return nil, err
+		}
+		agent := &Agent{Dna: dna}
+		if i < len(cp.Operators) {
+			agent.Operators = cp.Operators[i]
+		}
+		agent.Evaluate(s)
+		s.agents = append(s.agents, agent)
+	}
+
+	if cp.Best != nil {
+		if err := cp.Best.Dna.Validate(s.Depots, s.Customers); err != nil {
+			return nil, err
+		}
+		s.best = cp.Best.Copy()
+		s.best.Evaluate(s)
+	}
+
+	s.setParameters(cp.Parameters)
+	s.generation = cp.Generation + 1
+	s.seed = cp.Seed
+	s.elapsed = cp.Elapsed
+
+	return s, nil
+}
+
+// checkpoint takes a checkpoint of the solver's state
+// at the end of the generation.
+func (s *Solver) checkpoint(info GenerationInfo) *Checkpoint {
+	cp := &Checkpoint{
+		Generation: s.generation,
+		Seed:       s.seed,
+		Elapsed:    info.Elapsed,
+		Parameters: s.parameters(),
+	}
+	for _, agent := range s.agents {
+		agent = agent.Copy()
+		cp.Population = append(cp.Population, agent.Dna)
+		cp.Operators = append(cp.Operators, agent.Operators)
+	}
+	// The generation's best agent is not yet taken into account.
+	best := s.best
+	if best == nil || info.BestAgent.Fitness.improves(best.Fitness) {
+		best = info.BestAgent
+	}
+	cp.Best = best.Copy()
+
+	return cp
+}
+
+// parameters returns the solver's current parameters.
+func (s *Solver) parameters() Parameters {
+	return Parameters{
+		SelectionSize:                       s.SelectionSize,
+		SelectionMethod:                     s.SelectionMethod,
+		RandomChanceRouteSplit:              s.RandomChanceRouteSplit,
+		RandomChanceDepotRelocation:         s.RandomChanceDepotRelocation,
+		RandomChanceEvaluateOuterDepotRoute: s.RandomChanceEvaluateOuterDepotRoute,
+		RandomChanceBorderlineRelocation:    s.RandomChanceBorderlineRelocation,
+		RandomChanceVehicleSwap:             s.RandomChanceVehicleSwap,
+	}
+}
+
+// setParameters overrides the solver's current parameters.
+func (s *Solver) setParameters(p Parameters) {
+	s.SelectionSize = p.SelectionSize
+	s.SelectionMethod = p.SelectionMethod
+	s.RandomChanceRouteSplit = p.RandomChanceRouteSplit
+	s.RandomChanceDepotRelocation = p.RandomChanceDepotRelocation
+	s.RandomChanceEvaluateOuterDepotRoute = p.RandomChanceEvaluateOuterDepotRoute
+	s.RandomChanceBorderlineRelocation = p.RandomChanceBorderlineRelocation
+	s.RandomChanceVehicleSwap = p.RandomChanceVehicleSwap
+}
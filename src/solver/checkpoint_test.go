@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRestoredSolverContinuesRun(t *testing.T) {
+	cfg := SolverConfig{PopulationSize: 20, NumCPUs: 1, Seed: 1}
+	end := EndCondition{Generations: 20}
+
+	uninterrupted, err := NewSolver(newTestConfig(t, "p01", cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantImprovements := &improvementRecorder{from: 10}
+	uninterrupted.Subscribe(wantImprovements)
+	want := uninterrupted.Solve(context.Background(), end)
+
+	interrupted := cfg
+	interrupted.CheckpointInterval = 10
+	interrupted.CheckpointPath = filepath.Join(t.TempDir(), "checkpoint.json")
+	s, err := NewSolver(newTestConfig(t, "p01", interrupted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Solve(context.Background(), EndCondition{Generations: 10})
+
+	cp, err := LoadCheckpoint(interrupted.CheckpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreSolver(newTestConfig(t, "p01", cfg), cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotImprovements := &improvementRecorder{}
+	restored.Subscribe(gotImprovements)
+	got := restored.Solve(context.Background(), end)
+
+	if got.GenerationNumber != want.GenerationNumber {
+		t.Errorf("Expected generation %d, got %d", want.GenerationNumber, got.GenerationNumber)
+	}
+	if got.BestAgent.Fitness != want.BestAgent.Fitness {
+		t.Errorf("Expected best fitness %v, got %v", want.BestAgent.Fitness, got.BestAgent.Fitness)
+	}
+	if !reflect.DeepEqual(got.BestAgent.Operators, want.BestAgent.Operators) {
+		t.Errorf("Expected best operators %v, got %v", want.BestAgent.Operators, got.BestAgent.Operators)
+	}
+	if !reflect.DeepEqual(gotImprovements.improvements, wantImprovements.improvements) {
+		t.Errorf("Expected improvements %v, got %v", wantImprovements.improvements, gotImprovements.improvements)
+	}
+	if cp.Elapsed <= 0 || got.Elapsed < cp.Elapsed {
+		t.Errorf("Expected elapsed time to continue from %v, got %v", cp.Elapsed, got.Elapsed)
+	}
+}
+
+// improvementRecorder records the fitness and operators
+// of improvements from a generation on.
+type improvementRecorder struct {
+	BaseObserver
+	from         int
+	improvements []Improvement
+}
+
+func (ir *improvementRecorder) OnImprovement(improvement Improvement) {
+	if improvement.Info.GenerationNumber < ir.from {
+		return
+	}
+	improvement.Info = GenerationInfo{GenerationNumber: improvement.Info.GenerationNumber}
+	improvement.AddedRoutes, improvement.RemovedRoutes = nil, nil
+	ir.improvements = append(ir.improvements, improvement)
+}
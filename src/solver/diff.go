@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// DNADiff describes the differences between two solutions.
+type DNADiff struct {
+	// SharedEdges are the edges traveled in both solutions, while
+	// OnlyA and OnlyB are only traveled in one of them.
+	SharedEdges map[Edge]bool
+	OnlyA       map[Edge]bool
+	OnlyB       map[Edge]bool
+
+	// RoutesA and RoutesB are the routes of each solution that
+	// are not found in the other. Reversed routes are only found
+	// if reversing them does not change them (see Directed).
+	RoutesA []*Route
+	RoutesB []*Route
+}
+
+// Diff compares two solutions of the problem with the depots.
+// directed tells if reversing a route may change it.
+func Diff(a, b DNA, depots entities.Depots, directed bool) DNADiff {
+	diff := DNADiff{
+		SharedEdges: map[Edge]bool{},
+		OnlyA:       map[Edge]bool{},
+		OnlyB:       map[Edge]bool{},
+	}
+
+	edgesA, edgesB := a.Edges(depots), b.Edges(depots)
+	for edge := range edgesA {
+		if edgesB[edge] {
+			diff.SharedEdges[edge] = true
+		} else {
+			diff.OnlyA[edge] = true
+		}
+	}
+	for edge := range edgesB {
+		if !edgesA[edge] {
+			diff.OnlyB[edge] = true
+		}
+	}
+
+	diff.RoutesA = uniqueRoutes(a, b, depots, directed)
+	diff.RoutesB = uniqueRoutes(b, a, depots, directed)
+
+	return diff
+}
+
+// uniqueRoutes returns the non-empty routes of
+// the dna that are not found in the other.
+func uniqueRoutes(dna, other DNA, depots entities.Depots, directed bool) (routes []*Route) {
+	reversible := func(route *Route) bool {
+		return !directed && !depots[route.DepotID].OpenRoutes
+	}
+	keys := map[string]bool{}
+	for _, route := range other {
+		keys[route.key(reversible(route))] = true
+	}
+	for _, route := range dna {
+		if len(route.Path) > 0 && !keys[route.key(reversible(route))] {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// key identifies the route by its depot, vehicle type and path. A
+// reversible route and its reverse share a key. Open routes start and
+// end differently when reversed, so they are never reversible.
+func (route *Route) key(reversible bool) string {
+	forward := fmt.Sprint(route.DepotID, route.VehicleType, route.Path)
+	if !reversible {
+		return forward
+	}
+
+	reversed := make([]int, len(route.Path))
+	for i, cID := range route.Path {
+		reversed[len(route.Path)-1-i] = cID
+	}
+	backward := fmt.Sprint(route.DepotID, route.VehicleType, reversed)
+
+	if backward < forward {
+		return backward
+	}
+	return forward
+}
+
+// Directed returns true if reversing a closed route may change its
+// cost or lateness, as the metric is not symmetric or the problem
+// has time windows.
+func Directed(metric entities.Metric, depots entities.Depots, customers entities.Customers) bool {
+	return !entities.IsSymmetric(metric) || hasTimeWindows(depots, customers)
+}
@@ -0,0 +1,78 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestDiff(t *testing.T) {
+	depots := entities.Depots{0: {}, 1: {OpenRoutes: true}}
+	a := DNA{
+		{DepotID: 0, Path: []int{1, 2, 3}},
+		{DepotID: 1, Path: []int{4, 5}},
+	}
+
+	tests := []struct {
+		name             string
+		b                DNA
+		directed         bool
+		onlyA, onlyB     int
+		routesA, routesB int
+	}{
+		{"identical", DNA{
+			{DepotID: 0, Path: []int{1, 2, 3}},
+			{DepotID: 1, Path: []int{4, 5}},
+		}, false, 0, 0, 0, 0},
+		{"closed route reversed", DNA{
+			{DepotID: 0, Path: []int{3, 2, 1}},
+			{DepotID: 1, Path: []int{4, 5}},
+		}, false, 0, 0, 0, 0},
+		// The edges are the same, but the route is not.
+		{"directed closed route reversed", DNA{
+			{DepotID: 0, Path: []int{3, 2, 1}},
+			{DepotID: 1, Path: []int{4, 5}},
+		}, true, 0, 0, 1, 1},
+		// (1 4 5) travels 1-4 and 4-5, while (1 5 4) travels 1-5 and 4-5.
+		{"open route reversed", DNA{
+			{DepotID: 0, Path: []int{1, 2, 3}},
+			{DepotID: 1, Path: []int{5, 4}},
+		}, false, 1, 1, 1, 1},
+		{"customer moved", DNA{
+			{DepotID: 0, Path: []int{1, 2}},
+			{DepotID: 1, Path: []int{4, 5, 3}},
+		}, false, 2, 2, 2, 2},
+	}
+
+	for _, test := range tests {
+		diff := Diff(a, test.b, depots, test.directed)
+		if len(diff.OnlyA) != test.onlyA || len(diff.OnlyB) != test.onlyB {
+			t.Errorf("%s: got %d and %d unique edges, want %d and %d", test.name, len(diff.OnlyA), len(diff.OnlyB), test.onlyA, test.onlyB)
+		}
+		if len(diff.RoutesA) != test.routesA || len(diff.RoutesB) != test.routesB {
+			t.Errorf("%s: got %d and %d unique routes, want %d and %d", test.name, len(diff.RoutesA), len(diff.RoutesB), test.routesA, test.routesB)
+		}
+	}
+}
+
+func TestDirected(t *testing.T) {
+	depots := entities.Depots{0: {}}
+	customers := entities.Customers{1: {ID: 1}}
+	tests := []struct {
+		name      string
+		metric    entities.Metric
+		customers entities.Customers
+		want      bool
+	}{
+		{"euclidean", entities.Euclidean{}, customers, false},
+		{"symmetric matrix", entities.NewDistanceMatrix([][]float64{{0, 2}, {2, 0}}), customers, false},
+		{"asymmetric matrix", entities.NewDistanceMatrix([][]float64{{0, 2}, {3, 0}}), customers, true},
+		{"time windows", entities.Euclidean{}, entities.Customers{1: {ID: 1, TimeWindow: &entities.TimeWindow{End: 10}}}, true},
+	}
+
+	for _, test := range tests {
+		if got := Directed(test.metric, depots, test.customers); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
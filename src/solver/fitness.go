@@ -13,30 +13,67 @@ type Fitness struct {
 	Total      float64
 	Distance   float64
 	OverDemand float64
+
+	// Cost is the fixed and per-distance cost of
+	// the vehicles driving the routes.
+	Cost float64
+
+	// TimeWindowViolation is the total time by which service
+	// starts after customers' time windows close and vehicles
+	// return after their depots close.
+	TimeWindowViolation float64
+
+	// OverDuration is the total time by which routes exceed
+	// their vehicles' maximum route duration.
+	OverDuration float64
 }
 
 func (f *Fitness) Clear() {
 	f.Total = 0
 	f.Distance = 0
+	f.Cost = 0
 	f.OverDemand = 0
+	f.TimeWindowViolation = 0
+	f.OverDuration = 0
 }
 
 // CalculateTotal calculates the total error for the fitness
-// given distance and over-demand.
+// given cost, over-demand, time window violation and over-duration.
 func (f *Fitness) CalculateTotal() {
-	f.Total = f.Distance
+	f.Total = f.Cost
 	f.Total += 100 * math.Pow(f.OverDemand, 2)
+	f.Total += 100 * math.Pow(f.TimeWindowViolation, 2)
+	f.Total += 100 * math.Pow(f.OverDuration, 2)
+}
+
+// IsFeasible returns true if the fitness has no
+// constraint violations.
+func (f *Fitness) IsFeasible() bool {
+	return f.OverDemand == 0 && f.TimeWindowViolation == 0 && f.OverDuration == 0
+}
+
+// improves returns true if the fitness is better than the
+// previous fitness. A feasible fitness is better than an
+// infeasible one, whatever their totals.
+func (f *Fitness) improves(previous Fitness) bool {
+	if f.IsFeasible() != previous.IsFeasible() {
+		return f.IsFeasible()
+	}
+	return f.Total < previous.Total
 }
 
 // Add adds a secondary fitness to this fitness.
 func (f *Fitness) Add(f2 *Fitness) {
 	f.Distance += f2.Distance
+	f.Cost += f2.Cost
 	f.OverDemand += f2.OverDemand
+	f.TimeWindowViolation += f2.TimeWindowViolation
+	f.OverDuration += f2.OverDuration
 	f.CalculateTotal()
 }
 
 // String returns a print-friendly string of
 // this fitness.
 func (f Fitness) String() string {
-	return fmt.Sprintf("Fitness(dist: %f, over-demand: %f, total: %f)", f.Distance, f.OverDemand, f.Total)
+	return fmt.Sprintf("Fitness(dist: %f, cost: %f, over-demand: %f, time-window-violation: %f, over-duration: %f, total: %f)", f.Distance, f.Cost, f.OverDemand, f.TimeWindowViolation, f.OverDuration, f.Total)
 }
@@ -12,35 +12,31 @@ type DNA []*Route
 
 // NewDNA creates a new random DNA where a depot's routes
 // consist of customers closest to the depot.
-func NewDNA(depots entities.Depots, customers entities.Customers) (dna DNA) {
-	depotCustomers := make(map[int]entities.Customers)
-	for depotID := range depots {
-		depotCustomers[depotID] = make(entities.Customers)
-	}
-	for cID, customer := range customers {
-		closestDepotID := 0
-		closestDepotDistance := 999999999.0
-		for dID, depot := range depots {
-			dist := distance(depot, customer)
-			if dist < closestDepotDistance {
-				closestDepotDistance = dist
-				closestDepotID = dID
-			}
-		}
-		depotCustomers[closestDepotID][cID] = customer
+// Borderline customers in the grouping are assigned
+// to a random one of their candidate depots.
+// Every depot must have at least one vehicle.
+func NewDNA(depots entities.Depots, customers entities.Customers, grouping Grouping, rng *rand.Rand) (dna DNA) {
+	depotCustomers := make(map[int][]int)
+	for _, cID := range customers.IDs() {
+		depotID := grouping.RandomDepot(cID, rng)
+		depotCustomers[depotID] = append(depotCustomers[depotID], cID)
 	}
 
-	for depotID, remainingCustomers := range depotCustomers {
+	for _, depotID := range depots.IDs() {
 		depotRoutes := []*Route{}
-		for j := 0; j < depots[depotID].MaxNumVehicles; j++ {
-			depotRoutes = append(depotRoutes, &Route{DepotID: depotID})
+		for vehicleType, vehicle := range depots[depotID].VehicleTypes() {
+			for j := 0; j < vehicle.Count; j++ {
+				depotRoutes = append(depotRoutes, &Route{DepotID: depotID, VehicleType: vehicleType})
+			}
 		}
 
-		for i := 0; len(remainingCustomers) != 0; i = (i + 1) % len(depotRoutes) {
-			cID, customer := remainingCustomers.RandomSelect()
-			delete(remainingCustomers, cID)
-			nucleotide := depotRoutes[i]
-			nucleotide.Path = append(nucleotide.Path, customer.ID)
+		remainingCustomers := depotCustomers[depotID]
+		rng.Shuffle(len(remainingCustomers), func(i, j int) {
+			remainingCustomers[i], remainingCustomers[j] = remainingCustomers[j], remainingCustomers[i]
+		})
+		for i, cID := range remainingCustomers {
+			nucleotide := depotRoutes[i%len(depotRoutes)]
+			nucleotide.Path = append(nucleotide.Path, customers[cID].ID)
 		}
 
 		dna = append(dna, depotRoutes...)
@@ -50,6 +46,7 @@ func NewDNA(depots entities.Depots, customers entities.Customers) (dna DNA) {
 }
 
 // String returns a print-friendly description of the dna.
+// Its routes are described as closed routes.
 func (dna DNA) String() string {
 	text := ""
 	for _, route := range dna {
@@ -59,8 +56,146 @@ func (dna DNA) String() string {
 }
 
 // GetRandomRoute returns a random route in the dna.
-func (dna DNA) GetRandomRoute() *Route {
-	return dna[rand.Int63n(int64(len(dna)))]
+func (dna DNA) GetRandomRoute(rng *rand.Rand) *Route {
+	return dna[rng.Int63n(int64(len(dna)))]
+}
+
+// Validate checks that the dna only references existing depots
+// and vehicle types, has no more routes of each vehicle type than
+// its depot has vehicles and visits every customer exactly once.
+func (dna DNA) Validate(depots entities.Depots, customers entities.Customers) error {
+	visited := map[int]bool{}
+	numRoutes := map[[2]int]int{}
+	for _, route := range dna {
+		if _, ok := depots[route.DepotID]; !ok {
+			return fmt.Errorf("Unknown depot %d", route.DepotID)
+		}
+		vehicles := depots[route.DepotID].VehicleTypes()
+		if route.VehicleType < 0 || route.VehicleType >= len(vehicles) {
+			return fmt.Errorf("Unknown vehicle type %d of depot %d", route.VehicleType, route.DepotID)
+		}
+		vehicle := [2]int{route.DepotID, route.VehicleType}
+		numRoutes[vehicle]++
+		if count := vehicles[route.VehicleType].Count; numRoutes[vehicle] > count {
+			return fmt.Errorf("Depot %d has more than %d routes of vehicle type %d", route.DepotID, count, route.VehicleType)
+		}
+		for _, cID := range route.Path {
+			if _, ok := customers[cID]; !ok {
+				return fmt.Errorf("Unknown customer %d", cID)
+			}
+			if visited[cID] {
+				return fmt.Errorf("Customer %d is visited more than once", cID)
+			}
+			visited[cID] = true
+		}
+	}
+
+	if len(visited) != len(customers) {
+		return fmt.Errorf("%d of %d customers are not visited", len(customers)-len(visited), len(customers))
+	}
+
+	return nil
+}
+
+// pad returns the dna with empty routes added for the depots'
+// unused vehicles, so that it has a route for every vehicle
+// like the dna created by NewDNA. Solutions only list the
+// routes that are used.
+func (dna DNA) pad(depots entities.Depots) DNA {
+	numRoutes := map[[2]int]int{}
+	for _, route := range dna {
+		numRoutes[[2]int{route.DepotID, route.VehicleType}]++
+	}
+
+	for _, depotID := range depots.IDs() {
+		for vehicleType, vehicle := range depots[depotID].VehicleTypes() {
+			for j := numRoutes[[2]int{depotID, vehicleType}]; j < vehicle.Count; j++ {
+				dna = append(dna, &Route{DepotID: depotID, VehicleType: vehicleType})
+			}
+		}
+	}
+
+	return dna
+}
+
+// Edge is an undirected edge between two nodes in a route.
+// Customers are identified by their ID and depots
+// by -(depot ID + 1).
+type Edge [2]int
+
+// newEdge creates an edge with its nodes in ascending order.
+func newEdge(a, b int) Edge {
+	if a > b {
+		a, b = b, a
+	}
+	return Edge{a, b}
+}
+
+// Edges returns the set of edges traveled in the dna.
+// The depots tell which routes are open.
+func (dna DNA) Edges(depots entities.Depots) map[Edge]bool {
+	edges := map[Edge]bool{}
+	for _, route := range dna {
+		for _, edge := range route.Edges(depots[route.DepotID].OpenRoutes) {
+			edges[edge] = true
+		}
+	}
+	return edges
+}
+
+// Edges returns the edges of the route in the order they are
+// traveled, from and back to the depot. Open routes end at
+// the last customer.
+func (route *Route) Edges(open bool) (edges []Edge) {
+	if len(route.Path) == 0 {
+		return nil
+	}
+	prev := -(route.DepotID + 1)
+	for _, cID := range route.Path {
+		edges = append(edges, newEdge(prev, cID))
+		prev = cID
+	}
+	if open {
+		return edges
+	}
+	return append(edges, newEdge(prev, -(route.DepotID+1)))
+}
+
+// EdgeDistance returns the share of the dna's edges
+// not found in the provided edge set.
+func (dna DNA) EdgeDistance(depots entities.Depots, edges map[Edge]bool) float64 {
+	return edgeDistance(dna.Edges(depots), edges)
+}
+
+// edgeDistance returns the share of the own
+// edges not found in the other edge set.
+func edgeDistance(own, edges map[Edge]bool) float64 {
+	if len(own) == 0 {
+		return 0
+	}
+
+	numDifferent := 0
+	for edge := range own {
+		if !edges[edge] {
+			numDifferent++
+		}
+	}
+
+	return float64(numDifferent) / float64(len(own))
+}
+
+// FindCustomer returns the route visiting the customer and
+// the customer's index in the route's path. If no route visits
+// the customer, the returned route is nil.
+func (dna DNA) FindCustomer(cID int) (*Route, int) {
+	for _, route := range dna {
+		for i, _cID := range route.Path {
+			if _cID == cID {
+				return route, i
+			}
+		}
+	}
+	return nil, -1
 }
 
 // RemoveRouteNodes removes all customers from the dna that
@@ -88,17 +223,31 @@ func (dna DNA) RemoveRouteNodes(route *Route) {
 type Route struct {
 	DepotID int
 	Path    []int
+
+	// VehicleType is the index of the route's vehicle
+	// type in its depot's vehicle types.
+	VehicleType int
 }
 
-// GetRandomRoute returns a random route in the route.
+// String returns the route as a closed route (see Text),
+// as the route does not know if it is open.
 func (route Route) String() string {
+	return route.Text(false)
+}
+
+// Text returns the route's depot followed by its customers in
+// the order they are visited and, unless the route is open,
+// the depot it returns to.
+func (route Route) Text(open bool) string {
 	text := ""
 
 	text += fmt.Sprintf("(%d", route.DepotID)
 	for _, cID := range route.Path {
-		text += fmt.Sprintf(" %d ", cID)
+		text += fmt.Sprintf(" %d", cID)
+	}
+	if !open {
+		text += fmt.Sprintf(" %d", route.DepotID)
 	}
-	text += fmt.Sprint(route.DepotID)
 	text += ")"
 
 	return text
@@ -0,0 +1,71 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestRouteString(t *testing.T) {
+	route := Route{DepotID: 2, Path: []int{5, 1, 3}}
+	if got, want := route.String(), "(2 5 1 3 2)"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+	if got, want := route.Text(true), "(2 5 1 3)"; got != want {
+		t.Errorf("Got %q for the open route, want %q", got, want)
+	}
+}
+
+func newTestDepots() entities.Depots {
+	return entities.Depots{
+		0: {MaxNumVehicles: 1, MaxVehicleLoad: 10},
+		1: {Fleet: []entities.VehicleType{{Count: 1, MaxLoad: 10}, {Count: 2, MaxLoad: 5}}},
+	}
+}
+
+func TestDNAValidate(t *testing.T) {
+	customers := entities.Customers{1: {ID: 1}, 2: {ID: 2}}
+	tests := []struct {
+		name string
+		dna  DNA
+		err  string
+	}{
+		{"valid", DNA{{DepotID: 0, Path: []int{1}}, {DepotID: 1, VehicleType: 1, Path: []int{2}}, {DepotID: 1, VehicleType: 1}}, ""},
+		{"unknown depot", DNA{{DepotID: 2, Path: []int{1, 2}}}, "Unknown depot 2"},
+		{"unknown vehicle type", DNA{{DepotID: 1, VehicleType: 2, Path: []int{1, 2}}}, "Unknown vehicle type 2 of depot 1"},
+		{"too many routes", DNA{{DepotID: 0, Path: []int{1}}, {DepotID: 0, Path: []int{2}}}, "Depot 0 has more than 1 routes of vehicle type 0"},
+		{"too many empty routes", DNA{{DepotID: 0, Path: []int{1, 2}}, {DepotID: 0}}, "Depot 0 has more than 1 routes of vehicle type 0"},
+		{"too many routes of a type", DNA{{DepotID: 1, Path: []int{1}}, {DepotID: 1, Path: []int{2}}}, "Depot 1 has more than 1 routes of vehicle type 0"},
+		{"unknown customer", DNA{{DepotID: 0, Path: []int{1, 2, 3}}}, "Unknown customer 3"},
+		{"visited twice", DNA{{DepotID: 0, Path: []int{1, 2, 1}}}, "Customer 1 is visited more than once"},
+		{"not visited", DNA{{DepotID: 0, Path: []int{2}}}, "1 of 2 customers are not visited"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.dna.Validate(newTestDepots(), customers)
+			if test.err == "" && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("Expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestDNAPad(t *testing.T) {
+	dna := DNA{{DepotID: 1, VehicleType: 1, Path: []int{1, 2}}}
+	padded := dna.pad(newTestDepots())
+
+	want := DNA{
+		{DepotID: 1, VehicleType: 1, Path: []int{1, 2}},
+		{DepotID: 0},
+		{DepotID: 1},
+		{DepotID: 1, VehicleType: 1},
+	}
+	if !reflect.DeepEqual(padded, want) {
+		t.Errorf("Expected %v, got %v", want, padded)
+	}
+}
@@ -0,0 +1,184 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// GeoJSONOptions configures the GeoJSON export.
+type GeoJSONOptions struct {
+	// LonLat interprets X and Y as longitude and latitude.
+	// Coordinates are then validated and routes are given
+	// their great-circle length in kilometres. Otherwise
+	// coordinates are passed through as they are.
+	LonLat bool
+}
+
+// geoJSONFeature is a GeoJSON feature.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// geoJSONGeometry is a Point or LineString geometry.
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// geoJSONVehicleType describes a type of vehicle in a depot's
+// fleet, with the fields of problem documents.
+type geoJSONVehicleType struct {
+	Name         string  `json:"name,omitempty"`
+	Count        int     `json:"count"`
+	Capacity     float64 `json:"capacity"`
+	MaxDuration  float64 `json:"max_duration,omitempty"`
+	FixedCost    float64 `json:"fixed_cost,omitempty"`
+	DistanceCost float64 `json:"distance_cost"`
+}
+
+// WriteGeoJSON writes the depots, customers and the agent's routes
+// as a GeoJSON feature collection. Depots and customers are Point
+// features and each route is a LineString feature. Depots have
+// their fleet, also if all of their vehicles are alike.
+func (s *Solver) WriteGeoJSON(w io.Writer, agent *Agent, opts GeoJSONOptions) error {
+	features := []geoJSONFeature{}
+
+	point := func(l entities.Location) ([]float64, error) {
+		x, y := l.GetPosition()
+		if opts.LonLat && (math.Abs(x) > 180 || math.Abs(y) > 90) {
+			return nil, fmt.Errorf("Coordinates [%f, %f] are not a longitude and latitude", x, y)
+		}
+		return []float64{x, y}, nil
+	}
+
+	for _, id := range s.Depots.IDs() {
+		depot := s.Depots[id]
+		coordinates, err := point(depot)
+		if err != nil {
+			return err
+		}
+		fleet := []geoJSONVehicleType{}
+		for _, vehicle := range s.fleets[id] {
+			fleet = append(fleet, geoJSONVehicleType{
+				Name:         vehicle.Name,
+				Count:        vehicle.Count,
+				Capacity:     vehicle.MaxLoad,
+				MaxDuration:  vehicle.MaxRouteDuration,
+				FixedCost:    vehicle.FixedCost,
+				DistanceCost: vehicle.DistanceCost,
+			})
+		}
+		features = append(features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONGeometry{Type: "Point", Coordinates: coordinates},
+			Properties: map[string]interface{}{
+				"kind":        "depot",
+				"depot_id":    id,
+				"name":        depot.Name,
+				"fleet":       fleet,
+				"open_routes": depot.OpenRoutes,
+			},
+		})
+	}
+
+	for _, id := range s.Customers.IDs() {
+		customer := s.Customers[id]
+		coordinates, err := point(customer)
+		if err != nil {
+			return err
+		}
+		features = append(features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONGeometry{Type: "Point", Coordinates: coordinates},
+			Properties: map[string]interface{}{
+				"kind":             "customer",
+				"customer_id":      id,
+				"name":             customer.Name,
+				"demand":           customer.Demand,
+				"service_duration": customer.ServiceDuration,
+			},
+		})
+	}
+
+	vehicles := map[int]int{}
+	for _, route := range agent.Dna {
+		if len(route.Path) == 0 {
+			continue
+		}
+		vehicles[route.DepotID]++
+
+		depot := s.Depots[route.DepotID]
+		locations := []entities.Location{depot}
+		for _, cID := range route.Path {
+			locations = append(locations, s.Customers[cID])
+		}
+		if !depot.OpenRoutes {
+			locations = append(locations, depot)
+		}
+
+		coordinates := [][]float64{}
+		for _, l := range locations {
+			c, err := point(l)
+			if err != nil {
+				return err
+			}
+			coordinates = append(coordinates, c)
+		}
+
+		stats := s.RouteStats(route)
+		properties := map[string]interface{}{
+			"kind":          "route",
+			"depot_id":      route.DepotID,
+			"vehicle":       vehicles[route.DepotID],
+			"customers":     route.Path,
+			"load":          stats.Load,
+			"distance":      stats.Distance,
+			"duration":      stats.Duration,
+			"cost":          stats.Cost,
+			"over_capacity": stats.OverLoad > 0,
+		}
+		if depot.Fleet != nil {
+			properties["vehicle_type"] = depot.Fleet[route.VehicleType].Name
+		}
+		if opts.LonLat {
+			km := 0.0
+			for i := 0; i < len(coordinates)-1; i++ {
+				km += haversine(coordinates[i], coordinates[i+1])
+			}
+			properties["distance_km"] = km
+		}
+
+		features = append(features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: properties,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+}
+
+// haversine returns the great-circle distance in kilometres
+// between two [longitude, latitude] coordinates.
+func haversine(a, b []float64) float64 {
+	const earthRadius = 6371.0
+
+	toRadians := func(deg float64) float64 {
+		return deg * math.Pi / 180
+	}
+	lat1, lat2 := toRadians(a[1]), toRadians(b[1])
+	dLat := lat2 - lat1
+	dLon := toRadians(b[0] - a[0])
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,146 @@
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// geoJSON is a decoded GeoJSON feature collection.
+type geoJSON struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"features"`
+}
+
+func newGeoJSONTestSolver(t *testing.T) *Solver {
+	depots := entities.Depots{
+		0: {Name: "north", X: 10, Y: 50, MaxNumVehicles: 2, MaxVehicleLoad: 100},
+		1: {X: 11, Y: 50, Fleet: []entities.VehicleType{
+			{Name: "van", Count: 1, MaxLoad: 10, MaxRouteDuration: 500, DistanceCost: 1},
+			{Name: "truck", Count: 1, MaxLoad: 50, FixedCost: 20, DistanceCost: 2},
+		}},
+	}
+	customers := entities.Customers{
+		1: {ID: 1, X: 10, Y: 51, Demand: 5, ServiceDuration: 2},
+		2: {ID: 2, X: 11, Y: 51, Demand: 20},
+		3: {ID: 3, X: 12, Y: 50, Demand: 8},
+	}
+	s, err := NewSolver(SolverConfig{Depots: depots, Customers: customers, ProblemType: entities.MDVRP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	s := newGeoJSONTestSolver(t)
+	agent := &Agent{Dna: DNA{
+		{DepotID: 0, Path: []int{1}},
+		{DepotID: 0},
+		{DepotID: 1, VehicleType: 1, Path: []int{2, 3}},
+	}}
+
+	var buf bytes.Buffer
+	if err := s.WriteGeoJSON(&buf, agent, GeoJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var collection geoJSON
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 depots, 3 customers and 2 non-empty routes.
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 7 {
+		t.Fatalf("Expected a collection of 7 features, got %q with %d", collection.Type, len(collection.Features))
+	}
+
+	depot := collection.Features[0]
+	if depot.Geometry.Type != "Point" || string(depot.Geometry.Coordinates) != "[10,50]" {
+		t.Errorf("Expected the first depot at [10,50], got %s %s", depot.Geometry.Type, depot.Geometry.Coordinates)
+	}
+	if depot.Properties["kind"] != "depot" || depot.Properties["name"] != "north" {
+		t.Errorf("Unexpected depot properties %v", depot.Properties)
+	}
+	wantFleet := []interface{}{
+		map[string]interface{}{"name": "van", "count": 1.0, "capacity": 10.0, "max_duration": 500.0, "distance_cost": 1.0},
+		map[string]interface{}{"name": "truck", "count": 1.0, "capacity": 50.0, "fixed_cost": 20.0, "distance_cost": 2.0},
+	}
+	if fleet := collection.Features[1].Properties["fleet"]; !reflect.DeepEqual(fleet, wantFleet) {
+		t.Errorf("Expected fleet %v, got %v", wantFleet, fleet)
+	}
+
+	customer := collection.Features[3]
+	if customer.Properties["customer_id"] != 2.0 || customer.Properties["demand"] != 20.0 {
+		t.Errorf("Unexpected customer properties %v", customer.Properties)
+	}
+	if c := collection.Features[2]; c.Properties["service_duration"] != 2.0 {
+		t.Errorf("Expected a service duration of 2, got %v", c.Properties["service_duration"])
+	}
+
+	// (11, 50) -> (11, 51) -> (12, 50) -> (11, 50) travels 1 + sqrt(2) + 1.
+	route := collection.Features[6]
+	if route.Geometry.Type != "LineString" || string(route.Geometry.Coordinates) != "[[11,50],[11,51],[12,50],[11,50]]" {
+		t.Errorf("Unexpected route geometry %s %s", route.Geometry.Type, route.Geometry.Coordinates)
+	}
+	distance := 2 + math.Sqrt2
+	want := map[string]interface{}{
+		"kind":          "route",
+		"depot_id":      1.0,
+		"vehicle":       1.0,
+		"vehicle_type":  "truck",
+		"customers":     []interface{}{2.0, 3.0},
+		"load":          28.0,
+		"distance":      distance,
+		"duration":      distance,
+		"cost":          20 + 2*distance,
+		"over_capacity": false,
+	}
+	if !reflect.DeepEqual(route.Properties, want) {
+		t.Errorf("Expected route properties %v, got %v", want, route.Properties)
+	}
+	if first := collection.Features[5]; first.Properties["vehicle"] != 1.0 || first.Properties["duration"] != 4.0 {
+		t.Errorf("Expected vehicle 1 with a duration of 4, got %v", first.Properties)
+	}
+}
+
+func TestWriteGeoJSONLonLat(t *testing.T) {
+	s := newGeoJSONTestSolver(t)
+	agent := &Agent{Dna: DNA{{DepotID: 0, Path: []int{1}}, {DepotID: 1, Path: []int{2, 3}}}}
+
+	var buf bytes.Buffer
+	if err := s.WriteGeoJSON(&buf, agent, GeoJSONOptions{LonLat: true}); err != nil {
+		t.Fatal(err)
+	}
+	var collection geoJSON
+	if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// The route travels a degree of latitude back and forth.
+	route := collection.Features[5]
+	want := 2 * 6371 * math.Pi / 180
+	if km, ok := route.Properties["distance_km"].(float64); !ok || math.Abs(km-want) > 1e-9 {
+		t.Errorf("Expected a distance of %v km, got %v", want, route.Properties["distance_km"])
+	}
+
+	s.Customers[2].Y = 91
+	err := s.WriteGeoJSON(&bytes.Buffer{}, agent, GeoJSONOptions{LonLat: true})
+	if err == nil || !strings.Contains(err.Error(), "not a longitude and latitude") {
+		t.Errorf("Expected invalid coordinates, got %v", err)
+	}
+	if err := s.WriteGeoJSON(&bytes.Buffer{}, agent, GeoJSONOptions{}); err != nil {
+		t.Errorf("Expected coordinates to be passed through, got %v", err)
+	}
+}
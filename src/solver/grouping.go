@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// Grouping describes which depots each customer
+// may be assigned to. The key is the customer's ID
+// and the value is the candidate depot IDs, ordered
+// from nearest to farthest.
+//
+// Customers with more than one candidate depot are
+// borderline customers: they lie (almost) as close to
+// another depot as to their nearest one.
+type Grouping map[int][]int
+
+// NewGrouping groups customers to depots, measuring
+// distances with the provided metric. A depot is a candidate for a customer if
+// (dist(c, depot) - dist(c, nearest)) / dist(c, nearest) <= bound,
+// so a bound of 0 assigns every customer to its nearest depot only.
+func NewGrouping(depots entities.Depots, customers entities.Customers, metric entities.Metric, bound float64) Grouping {
+	grouping := make(Grouping)
+
+	for cID, customer := range customers {
+		depotIDs := []int{}
+		distances := map[int]float64{}
+		for dID, depot := range depots {
+			depotIDs = append(depotIDs, dID)
+			distances[dID] = metric.Distance(depot, customer)
+		}
+		sort.Slice(depotIDs, func(i, j int) bool {
+			if distances[depotIDs[i]] == distances[depotIDs[j]] {
+				return depotIDs[i] < depotIDs[j]
+			}
+			return distances[depotIDs[i]] < distances[depotIDs[j]]
+		})
+
+		nearest := distances[depotIDs[0]]
+		candidates := []int{depotIDs[0]}
+		for _, dID := range depotIDs[1:] {
+			if distances[dID]-nearest > bound*nearest {
+				break
+			}
+			candidates = append(candidates, dID)
+		}
+
+		grouping[cID] = candidates
+	}
+
+	return grouping
+}
+
+// IsBorderline returns true if the customer can be
+// assigned to more than one depot.
+func (g Grouping) IsBorderline(cID int) bool {
+	return len(g[cID]) > 1
+}
+
+// Borderline returns the IDs of all borderline customers
+// in ascending order.
+func (g Grouping) Borderline() []int {
+	borderline := []int{}
+	for cID := range g {
+		if g.IsBorderline(cID) {
+			borderline = append(borderline, cID)
+		}
+	}
+	sort.Ints(borderline)
+	return borderline
+}
+
+// RandomDepot returns a random candidate depot for the customer.
+func (g Grouping) RandomDepot(cID int, rng *rand.Rand) int {
+	candidates := g[cID]
+	return candidates[rng.Intn(len(candidates))]
+}
@@ -0,0 +1,156 @@
+package solver
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MetricsFormat is the output format of a metrics recorder.
+type MetricsFormat string
+
+const (
+	CSV       MetricsFormat = "CSV"
+	JSONLines MetricsFormat = "JSONLines"
+)
+
+// MetricsRecord describes the state of a single generation.
+type MetricsRecord struct {
+	Generation       int     `json:"generation"`
+	Elapsed          float64 `json:"elapsed"`
+	BestFitness      float64 `json:"best_fitness"`
+	MeanFitness      float64 `json:"mean_fitness"`
+	WorstFitness     float64 `json:"worst_fitness"`
+	BestDistance     float64 `json:"best_distance"`
+	BestOverDemand   float64 `json:"best_over_demand"`
+	BestLateness     float64 `json:"best_lateness"`
+	BestOverDuration float64 `json:"best_over_duration"`
+	FeasibleRatio    float64 `json:"feasible_ratio"`
+	Diversity        float64 `json:"diversity"`
+}
+
+// NewMetricsRecord creates a record from generation info.
+// Elapsed time is given in seconds.
+func NewMetricsRecord(info GenerationInfo) MetricsRecord {
+	record := MetricsRecord{
+		Generation:    info.GenerationNumber,
+		Elapsed:       info.Elapsed.Seconds(),
+		MeanFitness:   info.MeanFitness,
+		WorstFitness:  info.WorstFitness,
+		FeasibleRatio: info.FeasibleRatio,
+		Diversity:     info.Diversity,
+	}
+	if info.BestAgent != nil {
+		record.BestFitness = info.BestAgent.Fitness.Total
+		record.BestDistance = info.BestAgent.Fitness.Distance
+		record.BestOverDemand = info.BestAgent.Fitness.OverDemand
+		record.BestLateness = info.BestAgent.Fitness.TimeWindowViolation
+		record.BestOverDuration = info.BestAgent.Fitness.OverDuration
+	}
+	return record
+}
+
+// csvHeader is the header row of CSV metrics.
+var csvHeader = []string{
+	"generation",
+	"elapsed",
+	"best_fitness",
+	"mean_fitness",
+	"worst_fitness",
+	"best_distance",
+	"best_over_demand",
+	"best_lateness",
+	"best_over_duration",
+	"feasible_ratio",
+	"diversity",
+}
+
+// values returns the record's values in the order of csvHeader.
+func (r MetricsRecord) values() []string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(r.Generation),
+		f(r.Elapsed),
+		f(r.BestFitness),
+		f(r.MeanFitness),
+		f(r.WorstFitness),
+		f(r.BestDistance),
+		f(r.BestOverDemand),
+		f(r.BestLateness),
+		f(r.BestOverDuration),
+		f(r.FeasibleRatio),
+		f(r.Diversity),
+	}
+}
+
+// MetricsRecorder writes per-generation metrics records.
+// Records are written in the order they are recorded, so a
+// recorder is typically subscribed to the solver as an observer.
+type MetricsRecorder struct {
+	BaseObserver
+
+	format  MetricsFormat
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	err     error
+}
+
+// NewMetricsRecorder creates a recorder writing to w in the provided format.
+func NewMetricsRecorder(w io.Writer, format MetricsFormat) (*MetricsRecorder, error) {
+	r := &MetricsRecorder{format: format}
+
+	switch format {
+	case CSV:
+		r.csv = csv.NewWriter(w)
+	case JSONLines:
+		r.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("Unknown metrics format %q", format)
+	}
+
+	return r, nil
+}
+
+// Record writes a record for the generation.
+func (r *MetricsRecorder) Record(info GenerationInfo) error {
+	record := NewMetricsRecord(info)
+
+	if r.format == JSONLines {
+		return r.json.Encode(record)
+	}
+
+	if !r.started {
+		if err := r.csv.Write(csvHeader); err != nil {
+			return err
+		}
+		r.started = true
+	}
+	if err := r.csv.Write(record.values()); err != nil {
+		return err
+	}
+	r.csv.Flush()
+	return r.csv.Error()
+}
+
+// OnGeneration records the generation. Recording stops at the
+// first error, which is returned by Err.
+func (r *MetricsRecorder) OnGeneration(info GenerationInfo) {
+	if r.err == nil {
+		r.err = r.Record(info)
+	}
+}
+
+// UsesDiversity returns true, as every record has the diversity.
+func (r *MetricsRecorder) UsesDiversity() bool {
+	return true
+}
+
+// Err returns the first error recording generations as an observer.
+func (r *MetricsRecorder) Err() error {
+	return r.err
+}
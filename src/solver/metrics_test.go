@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestGenerationInfo(generation int) GenerationInfo {
+	return GenerationInfo{
+		GenerationNumber: generation,
+		Elapsed:          1500 * time.Millisecond,
+		BestAgent: &Agent{Fitness: Fitness{
+			Total:               1330,
+			Distance:            20,
+			OverDemand:          1,
+			TimeWindowViolation: 2,
+			OverDuration:        3,
+		}},
+		MeanFitness:   1500.5,
+		WorstFitness:  2000,
+		FeasibleRatio: 0.25,
+		Diversity:     0.5,
+	}
+}
+
+func TestMetricsRecorderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := NewMetricsRecorder(&buf, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.OnGeneration(newTestGenerationInfo(1))
+	r.OnGeneration(newTestGenerationInfo(2))
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "generation,elapsed,best_fitness,mean_fitness,worst_fitness,best_distance,best_over_demand,best_lateness,best_over_duration,feasible_ratio,diversity\n" +
+		"1,1.5,1330,1500.5,2000,20,1,2,3,0.25,0.5\n" +
+		"2,1.5,1330,1500.5,2000,20,1,2,3,0.25,0.5\n"
+	if buf.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, buf.String())
+	}
+}
+
+func TestMetricsRecorderJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := NewMetricsRecorder(&buf, JSONLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.OnGeneration(newTestGenerationInfo(1))
+	r.OnGeneration(newTestGenerationInfo(2))
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], `{"generation":1,"elapsed":1.5,"best_fitness":1330,`) {
+		t.Errorf("Unexpected first line %s", lines[0])
+	}
+
+	var record MetricsRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if want := NewMetricsRecord(newTestGenerationInfo(2)); record != want {
+		t.Errorf("Expected %+v, got %+v", want, record)
+	}
+}
+
+func TestNewMetricsRecorderUnknownFormat(t *testing.T) {
+	if _, err := NewMetricsRecorder(&bytes.Buffer{}, "XML"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
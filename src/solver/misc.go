@@ -2,6 +2,7 @@ package solver
 
 import (
 	"math"
+	"time"
 
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
 )
@@ -19,11 +20,118 @@ type GenerationInfo struct {
 	BestAgent         *Agent
 	GenerationNumber  int
 	PopulationFitness Fitness
+
+	// MeanFitness and WorstFitness are the mean and
+	// highest total fitness (error) in the population.
+	MeanFitness  float64
+	WorstFitness float64
+
+	// FeasibleRatio is the share of agents without
+	// constraint violations.
+	FeasibleRatio float64
+
+	// Diversity is the mean share of edges that agents do
+	// not have in common with the best agent, between
+	// 0 (identical population) and 1. It is only calculated,
+	// and otherwise 0, if a subscribed observer uses it
+	// (see DiversityUser).
+	Diversity float64
+
+	// EdgeFrequency is the share of agents traveling each edge.
+	// A new map is made every generation, so it can be kept.
+	// It is only calculated, and otherwise nil, if a subscribed
+	// observer uses it (see EdgeFrequencyUser).
+	EdgeFrequency map[Edge]float64
+
+	// BestKnownCost is the best-known cost of the instance
+	// being solved, or 0 if the instance is unknown.
+	// Gap is the gap (%) between the best agent's distance
+	// and the best-known cost. It is 0 if the best agent is
+	// infeasible, as its distance is not comparable.
+	BestKnownCost float64
+	Gap           float64
+
+	// Elapsed is the time passed since the solver started.
+	Elapsed time.Duration
+
+	// CheckpointError is set if writing a checkpoint
+	// at the end of this generation failed.
+	CheckpointError error
+}
+
+// distance calculates the distance between two entity locations
+// using the solver's metric.
+func (s *Solver) distance(a, b entities.Location) float64 {
+	return s.Metric.Distance(a, b)
 }
 
-// distance calculates the distance between two entity location
-func distance(a, b entities.Location) float64 {
-	ax, ay := a.GetPosition()
-	bx, by := b.GetPosition()
-	return math.Sqrt(math.Pow(ax-bx, 2) + math.Pow(ay-by, 2))
+// vehicleType returns the vehicle type driving the route.
+func (s *Solver) vehicleType(route *Route) entities.VehicleType {
+	return s.fleets[route.DepotID][route.VehicleType]
+}
+
+// RouteStats describes a single route.
+type RouteStats struct {
+	// Load is the total demand of the route's customers.
+	Load float64
+
+	// Distance is the distance traveled on the route.
+	Distance float64
+
+	// Cost is the fixed and per-distance cost of the route's vehicle.
+	Cost float64
+
+	// Duration is the distance traveled plus the service
+	// duration of the route's customers and any waiting.
+	Duration float64
+
+	// OverLoad and OverDuration are how much the route exceeds
+	// its vehicle's maximum load and route duration.
+	OverLoad     float64
+	OverDuration float64
+
+	// Wait and Lateness are the route's total waiting time and
+	// time window violation, if the problem has time windows.
+	Wait     float64
+	Lateness float64
+}
+
+// IsFeasible returns true if the route is within its
+// vehicle's load and duration limits and time windows.
+func (rs RouteStats) IsFeasible() bool {
+	return rs.OverLoad == 0 && rs.OverDuration == 0 && rs.Lateness == 0
+}
+
+// RouteStats calculates the stats of the route.
+func (s *Solver) RouteStats(route *Route) (stats RouteStats) {
+	if len(route.Path) == 0 {
+		return
+	}
+
+	prev := entities.Location(s.Depots[route.DepotID])
+	for _, cID := range route.Path {
+		customer := s.Customers[cID]
+		stats.Distance += s.distance(prev, customer)
+		stats.Load += customer.Demand
+		stats.Duration += customer.ServiceDuration
+		prev = customer
+	}
+	if !s.Depots[route.DepotID].OpenRoutes {
+		stats.Distance += s.distance(prev, s.Depots[route.DepotID])
+	}
+	stats.Duration += stats.Distance
+	if s.timeWindows {
+		schedule := s.Schedule(route)
+		stats.Wait, stats.Lateness = schedule.Wait, schedule.Lateness
+		stats.Duration += stats.Wait
+	}
+
+	vehicle := s.vehicleType(route)
+	stats.Cost = vehicle.FixedCost + vehicle.DistanceCost*stats.Distance
+	stats.OverLoad = math.Max(stats.Load-vehicle.MaxLoad, 0)
+	if vehicle.MaxRouteDuration > 0 {
+		stats.OverDuration = math.Max(stats.Duration-vehicle.MaxRouteDuration, 0)
+	}
+
+	return
 }
@@ -0,0 +1,228 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// Observer is notified about the solver's progress. Observers are
+// subscribed with Subscribe and are notified in the order they were
+// subscribed, from the goroutine running Solve. Slow observers slow
+// the solver down, so expensive work should be handed off.
+// The best agent of the generation info passed to observers is
+// never nil, but observers that may also be called directly
+// should not rely on it.
+type Observer interface {
+	// OnStart is called once Solve starts.
+	OnStart(info StartInfo)
+
+	// OnGeneration is called after every generation.
+	OnGeneration(info GenerationInfo)
+
+	// OnImprovement is called after generations where
+	// the best agent improved. A feasible agent improves
+	// on an infeasible one, whatever their fitness.
+	OnImprovement(improvement Improvement)
+
+	// OnFinish is called with the final generation
+	// once Solve is done.
+	OnFinish(info GenerationInfo)
+}
+
+// EdgeFrequencyUser is implemented by observers using the
+// edge frequency of generations. Calculating it is costly, so
+// it is only done if a subscribed observer uses it.
+type EdgeFrequencyUser interface {
+	UsesEdgeFrequency() bool
+}
+
+// DiversityUser is implemented by observers using the diversity
+// of generations. Like the edge frequency, it compares the edges
+// of every agent, so it is only calculated if an observer uses it.
+type DiversityUser interface {
+	UsesDiversity() bool
+}
+
+// StartInfo describes the problem being solved.
+type StartInfo struct {
+	Instance    string
+	ProblemType entities.ProblemType
+	Depots      entities.Depots
+	Customers   entities.Customers
+
+	// RouteStats calculates the stats of a route.
+	RouteStats func(route *Route) RouteStats
+
+	// Directed is true if reversing a route may change it
+	// (see Directed), e.g. when comparing solutions.
+	Directed bool
+}
+
+// Improvement describes an improvement of the best agent.
+type Improvement struct {
+	// Info is the generation where the best agent improved.
+	Info GenerationInfo
+
+	// Previous and Current are the fitness of the
+	// previous and the new best agent.
+	Previous Fitness
+	Current  Fitness
+
+	// Operators are the operators that produced the new best agent.
+	Operators []Operator
+
+	// AddedRoutes are the new best agent's routes not found in the
+	// previous best agent, and RemovedRoutes the previous best
+	// agent's routes no longer found.
+	AddedRoutes   []*Route
+	RemovedRoutes []*Route
+}
+
+// newImprovement describes the improvement of the
+// generation's best agent over the previous one.
+func newImprovement(info GenerationInfo, previous *Agent, depots entities.Depots, directed bool) Improvement {
+	diff := Diff(previous.Dna, info.BestAgent.Dna, depots, directed)
+	return Improvement{
+		Info:          info,
+		Previous:      previous.Fitness,
+		Current:       info.BestAgent.Fitness,
+		Operators:     append([]Operator{}, info.BestAgent.Operators...),
+		AddedRoutes:   diff.RoutesB,
+		RemovedRoutes: diff.RoutesA,
+	}
+}
+
+// BaseObserver ignores all notifications. It is meant to be
+// embedded in observers only interested in some of them.
+type BaseObserver struct{}
+
+func (BaseObserver) OnStart(info StartInfo)                {}
+func (BaseObserver) OnGeneration(info GenerationInfo)      {}
+func (BaseObserver) OnImprovement(improvement Improvement) {}
+func (BaseObserver) OnFinish(info GenerationInfo)          {}
+
+// ObserverFunc is an observer notified of every generation.
+type ObserverFunc func(info GenerationInfo)
+
+func (f ObserverFunc) OnStart(info StartInfo)                {}
+func (f ObserverFunc) OnGeneration(info GenerationInfo)      { f(info) }
+func (f ObserverFunc) OnImprovement(improvement Improvement) {}
+func (f ObserverFunc) OnFinish(info GenerationInfo)          {}
+
+// Subscribe subscribes observers to the solver's progress.
+// It must not be called while the solver is running.
+func (s *Solver) Subscribe(observers ...Observer) {
+	s.observers = append(s.observers, observers...)
+}
+
+// usesEdgeFrequency returns true if a subscribed
+// observer uses the edge frequency of generations.
+func (s *Solver) usesEdgeFrequency() bool {
+	for _, o := range s.observers {
+		if user, ok := o.(EdgeFrequencyUser); ok && user.UsesEdgeFrequency() {
+			return true
+		}
+	}
+	return false
+}
+
+// usesDiversity returns true if a subscribed
+// observer uses the diversity of generations.
+func (s *Solver) usesDiversity() bool {
+	for _, o := range s.observers {
+		if user, ok := o.(DiversityUser); ok && user.UsesDiversity() {
+			return true
+		}
+	}
+	return false
+}
+
+// startInfo describes the problem being solved.
+func (s *Solver) startInfo() StartInfo {
+	return StartInfo{
+		Instance:    s.Instance,
+		ProblemType: s.ProblemType,
+		Depots:      s.Depots,
+		Customers:   s.Customers,
+		RouteStats:  s.RouteStats,
+		Directed:    s.directed,
+	}
+}
+
+// Logger is an observer writing the progress
+// as human-readable text, e.g. to the console.
+type Logger struct {
+	// ImprovementsOnly only logs generations
+	// where the best agent improved.
+	ImprovementsOnly bool
+
+	w    io.Writer
+	name string
+}
+
+// NewLogger creates a logger writing to w. The name,
+// e.g. the problem path, prefixes every entry.
+func NewLogger(w io.Writer, name string) *Logger {
+	return &Logger{w: w, name: name}
+}
+
+// OnStart does nothing.
+func (l *Logger) OnStart(info StartInfo) {}
+
+// OnGeneration writes the fitness of the generation.
+func (l *Logger) OnGeneration(info GenerationInfo) {
+	if l.ImprovementsOnly || info.BestAgent == nil {
+		return
+	}
+
+	fmt.Fprintf(l.w, "%s (generation %d)\n", l.name, info.GenerationNumber)
+	fmt.Fprintf(l.w, "\tBest error:  %v\n", info.BestAgent.Fitness)
+	fmt.Fprintf(l.w, "\tTotal error: %v\n", info.PopulationFitness)
+	l.writeGap(info)
+	fmt.Fprintln(l.w)
+}
+
+// OnImprovement writes the improvement and what produced it.
+func (l *Logger) OnImprovement(improvement Improvement) {
+	operators := []string{}
+	for _, operator := range improvement.Operators {
+		operators = append(operators, string(operator))
+	}
+
+	fmt.Fprintf(l.w, "%s (generation %d) improved %.2f -> %.2f by %s, %d routes replaced by %d\n",
+		l.name,
+		improvement.Info.GenerationNumber,
+		improvement.Previous.Total,
+		improvement.Current.Total,
+		strings.Join(operators, ", "),
+		len(improvement.RemovedRoutes),
+		len(improvement.AddedRoutes),
+	)
+}
+
+// OnFinish writes the final result.
+func (l *Logger) OnFinish(info GenerationInfo) {
+	fmt.Fprintf(l.w, "%s (finished after %d generations in %v)\n", l.name, info.GenerationNumber, info.Elapsed)
+	if info.BestAgent == nil {
+		fmt.Fprintf(l.w, "\tNo agents\n")
+		return
+	}
+	fmt.Fprintf(l.w, "\tBest error:  %v\n", info.BestAgent.Fitness)
+	l.writeGap(info)
+}
+
+// writeGap writes the gap to the best-known cost, if it is known.
+// Infeasible agents have no gap.
+func (l *Logger) writeGap(info GenerationInfo) {
+	if info.BestKnownCost == 0 {
+		return
+	}
+	if !info.BestAgent.Fitness.IsFeasible() {
+		fmt.Fprintf(l.w, "\tGap to BKS:  - (infeasible, BKS %.2f)\n", info.BestKnownCost)
+		return
+	}
+	fmt.Fprintf(l.w, "\tGap to BKS:  %.2f%% (BKS %.2f)\n", info.Gap, info.BestKnownCost)
+}
@@ -0,0 +1,73 @@
+package solver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWithoutBestAgent(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "p01")
+
+	logger.OnGeneration(GenerationInfo{})
+	logger.OnFinish(GenerationInfo{})
+
+	if !strings.Contains(buf.String(), "No agents") {
+		t.Errorf("Expected the missing agent to be logged, got %q", buf.String())
+	}
+}
+
+func TestEdgeFrequencyOnlyForUsers(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{PopulationSize: 10, NumCPUs: 1, Seed: 1})
+	s.initializeAgents()
+
+	if info := s.generationInfo(); info.EdgeFrequency != nil {
+		t.Error("Expected no edge frequency without users")
+	}
+
+	s.Subscribe(&edgeFrequencyUser{uses: false})
+	if info := s.generationInfo(); info.EdgeFrequency != nil {
+		t.Error("Expected no edge frequency when it is not used")
+	}
+
+	s.Subscribe(&edgeFrequencyUser{uses: true})
+	info := s.generationInfo()
+	if len(info.EdgeFrequency) == 0 {
+		t.Fatal("Expected the edge frequency")
+	}
+	for edge := range info.BestAgent.Dna.Edges(s.Depots) {
+		if f := info.EdgeFrequency[edge]; f <= 0 || f > 1 {
+			t.Errorf("Expected the best agent's edge %v to have a frequency in (0, 1], got %v", edge, f)
+		}
+	}
+}
+
+func TestDiversityOnlyForUsers(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{PopulationSize: 10, NumCPUs: 1, Seed: 1})
+	s.initializeAgents()
+
+	s.Subscribe(&edgeFrequencyUser{uses: true})
+	if info := s.generationInfo(); info.Diversity != 0 {
+		t.Errorf("Expected no diversity without users, got %v", info.Diversity)
+	}
+
+	metrics, err := NewMetricsRecorder(&bytes.Buffer{}, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Subscribe(metrics)
+	if info := s.generationInfo(); info.Diversity <= 0 || info.Diversity > 1 {
+		t.Errorf("Expected a diversity in (0, 1], got %v", info.Diversity)
+	}
+}
+
+// edgeFrequencyUser is an observer that may use the edge frequency.
+type edgeFrequencyUser struct {
+	BaseObserver
+	uses bool
+}
+
+func (u *edgeFrequencyUser) UsesEdgeFrequency() bool {
+	return u.uses
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// SavePopulation writes the DNA of all agents to a JSON
+// snapshot in the specified filePath.
+func SavePopulation(filePath string, agents Agents) error {
+	population := []DNA{}
+	for _, agent := range agents {
+		population = append(population, agent.Dna)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(population)
+}
+
+// LoadPopulation reads a population snapshot written
+// by SavePopulation.
+func LoadPopulation(filePath string) ([]DNA, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	population := []DNA{}
+	if err := json.NewDecoder(file).Decode(&population); err != nil {
+		return nil, err
+	}
+
+	return population, nil
+}
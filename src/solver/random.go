@@ -0,0 +1,34 @@
+package solver
+
+import "math/rand"
+
+// initialization is the generation number the random
+// number generators of the initial population are made for.
+const initialization = -1
+
+// rngs returns a random number generator for each thread in the
+// generation. The generators are derived from the solver's seed and
+// the generation alone, so a solver restored from a checkpoint draws
+// the same random numbers as one that was never interrupted. Runs are
+// only fully reproducible with a single thread, as threads replace
+// agents in the shared population in an unpredictable order.
+func (s *Solver) rngs(generation int) []*rand.Rand {
+	rngs := make([]*rand.Rand, s.threads.NumThreads)
+	for tid := range rngs {
+		rngs[tid] = rand.New(rand.NewSource(deriveSeed(s.seed, int64(generation), int64(tid))))
+	}
+	return rngs
+}
+
+// deriveSeed mixes the values into the seed with SplitMix64,
+// so that nearby values give unrelated seeds.
+func deriveSeed(seed int64, values ...int64) int64 {
+	x := uint64(seed)
+	for _, v := range values {
+		x += uint64(v) + 0x9e3779b97f4a7c15
+		x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+		x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+		x ^= x >> 31
+	}
+	return int64(x)
+}
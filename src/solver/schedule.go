@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+// Schedule describes when a route's customers are visited.
+// Travel times equal the distances traveled.
+type Schedule struct {
+	// Departure is when the vehicle leaves the depot and
+	// Return is when it is back. Vehicles on open routes
+	// do not return, so Return is when the last service ends.
+	Departure, Return float64
+
+	Visits []Visit
+
+	// Wait is the total time spent waiting for time windows to open.
+	Wait float64
+
+	// Lateness is the total time by which service starts after
+	// time windows close, including the time by which the
+	// vehicle returns after the depot closes.
+	Lateness float64
+}
+
+// Visit describes the visit to a customer on a route.
+type Visit struct {
+	CustomerID int
+
+	// Arrival is when the vehicle arrives at the customer. Service
+	// starts at Start, after waiting for the time window to open.
+	Arrival, Wait, Start float64
+}
+
+// Schedule calculates the schedule of the route. Vehicles leave
+// as the depot opens, or later if they would otherwise have
+// to wait at the first customer.
+func (s *Solver) Schedule(route *Route) (schedule Schedule) {
+	if len(route.Path) == 0 {
+		return
+	}
+
+	depot := s.Depots[route.DepotID]
+	if depot.OpeningHours != nil {
+		schedule.Departure = depot.OpeningHours.Start
+	}
+	if first := s.Customers[route.Path[0]]; first.TimeWindow != nil {
+		schedule.Departure = math.Max(schedule.Departure, first.TimeWindow.Start-s.distance(depot, first))
+	}
+
+	t := schedule.Departure
+	prev := entities.Location(depot)
+	for _, cID := range route.Path {
+		customer := s.Customers[cID]
+		visit := Visit{CustomerID: cID, Arrival: t + s.distance(prev, customer)}
+		visit.Start = visit.Arrival
+		if customer.TimeWindow != nil {
+			visit.Start = math.Max(visit.Arrival, customer.TimeWindow.Start)
+			schedule.Lateness += math.Max(visit.Start-customer.TimeWindow.End, 0)
+		}
+		visit.Wait = visit.Start - visit.Arrival
+		schedule.Wait += visit.Wait
+		schedule.Visits = append(schedule.Visits, visit)
+
+		t = visit.Start + customer.ServiceDuration
+		prev = customer
+	}
+
+	if depot.OpenRoutes {
+		schedule.Return = t
+		return
+	}
+
+	schedule.Return = t + s.distance(prev, depot)
+	if depot.OpeningHours != nil {
+		schedule.Lateness += math.Max(schedule.Return-depot.OpeningHours.End, 0)
+	}
+
+	return
+}
+
+// hasTimeWindows returns true if any depot has opening
+// hours or any customer has a time window.
+func hasTimeWindows(depots entities.Depots, customers entities.Customers) bool {
+	for _, depot := range depots {
+		if depot.OpeningHours != nil {
+			return true
+		}
+	}
+	for _, customer := range customers {
+		if customer.TimeWindow != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadSolution reads a solution in the Cordeau solution
+// format from the file in the specified filePath.
+func LoadSolution(filePath string) (DNA, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadSolution(file)
+}
+
+// ReadSolution reads a solution in the Cordeau solution format.
+// The first line holds the total cost and every following line
+// describes one route:
+//
+//	depot vehicle duration load 0 c_1 c_2 ... c_n 0
+//
+// Open routes do not end with the depot (0).
+// Depot numbers in the file start at 1, while depot IDs
+// start at 0. Costs, durations and loads are ignored, as
+// they are re-evaluated by the solver. The format has no
+// vehicle types, so routes get their depot's first type.
+func ReadSolution(r io.Reader) (dna DNA, err error) {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || line == 1 {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("Line %d: expected at least 4 fields, got %d", line, len(fields))
+		}
+
+		depot, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: invalid depot number: %v", line, err)
+		}
+
+		route := &Route{DepotID: depot - 1}
+		for _, field := range fields[4:] {
+			cID, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: invalid customer number: %v", line, err)
+			}
+			// The depot is written as 0 at both ends of the route.
+			if cID == 0 {
+				continue
+			}
+			route.Path = append(route.Path, cID)
+		}
+
+		dna = append(dna, route)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return dna, nil
+}
+
+// WriteSolution writes the agent's routes in the Cordeau solution format.
+// Open routes end at the last customer instead of the depot.
+func (s *Solver) WriteSolution(w io.Writer, agent *Agent) error {
+	if _, err := fmt.Fprintf(w, "%.2f\n", agent.Fitness.Distance); err != nil {
+		return err
+	}
+
+	vehicles := map[int]int{}
+	for _, route := range agent.Dna {
+		if len(route.Path) == 0 {
+			continue
+		}
+		vehicles[route.DepotID]++
+
+		stats := s.RouteStats(route)
+
+		path := "0"
+		for _, cID := range route.Path {
+			path += fmt.Sprintf(" %d", cID)
+		}
+		if !s.Depots[route.DepotID].OpenRoutes {
+			path += " 0"
+		}
+
+		if _, err := fmt.Fprintf(w, "%d\t%d\t%.2f\t%.0f\t%s\n",
+			route.DepotID+1,
+			vehicles[route.DepotID],
+			stats.Distance,
+			stats.Load,
+			path,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSolutionRoundTrip(t *testing.T) {
+	for _, open := range []bool{false, true} {
+		s := newTestSolver(t, "p01", SolverConfig{Seed: 1})
+		for _, depot := range s.Depots {
+			depot.OpenRoutes = open
+		}
+		agent := NewAgent(s, rand.New(rand.NewSource(1)))
+
+		var buf bytes.Buffer
+		if err := s.WriteSolution(&buf, agent); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[1:] {
+			if strings.HasSuffix(line, " 0") == open {
+				t.Errorf("Open routes %v: unexpected route end in %q", open, line)
+			}
+		}
+
+		dna, err := ReadSolution(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := DNA{}
+		for _, route := range agent.Dna {
+			if len(route.Path) > 0 {
+				want = append(want, route)
+			}
+		}
+		if !reflect.DeepEqual(dna, want) {
+			t.Errorf("Open routes %v: read %v, want %v", open, dna, want)
+		}
+	}
+}
+
+func TestReadSolution(t *testing.T) {
+	text := `576.87
+1 1 120.50 50 0 4 2 0
+
+2 1 80.00 30 0 7 1
+`
+	dna, err := ReadSolution(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DNA{
+		{DepotID: 0, Path: []int{4, 2}},
+		{DepotID: 1, Path: []int{7, 1}},
+	}
+	if !reflect.DeepEqual(dna, want) {
+		t.Errorf("Expected %v, got %v", want, dna)
+	}
+}
+
+func TestReadSolutionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"too few fields", "1\n1 1 10\n", "Line 2: expected at least 4 fields, got 3"},
+		{"invalid depot", "1\nx 1 10 5 0 1 0\n", "Line 2: invalid depot number"},
+		{"invalid customer", "1\n1 1 10 5 0 1 y 0\n", "Line 2: invalid customer number"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadSolution(strings.NewReader(test.text))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -1,18 +1,38 @@
 package solver
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"runtime"
+	"time"
 
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/problem"
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/threading"
 )
 
-// NOTE: NOT IN USE
 // EndCondition specifies the end-condition for the solver.
-// The solver stops when the conditions defined are met.
+// The solver stops when any of the conditions defined are met.
+// Zero-valued conditions are ignored.
 type EndCondition struct {
+	// Distance stops the solver once the best agent is
+	// feasible with a distance at or below this value.
 	Distance float64
+
+	// Generations stops the solver after this many generations.
+	Generations int
+}
+
+// isMet returns true if the generation meets the end condition.
+func (ec EndCondition) isMet(info GenerationInfo) bool {
+	if ec.Generations > 0 && info.GenerationNumber+1 >= ec.Generations {
+		return true
+	}
+	if ec.Distance > 0 && info.BestAgent.Fitness.IsFeasible() && info.BestAgent.Fitness.Distance <= ec.Distance {
+		return true
+	}
+	return false
 }
 
 // SolverConfig is the solver's config.
@@ -24,9 +44,63 @@ type SolverConfig struct {
 	NumCPUs         int
 	SelectionMethod Selector
 
+	// Instance is the name of the problem instance, e.g. "p01".
+	// Known instances have their gap to the best-known
+	// cost reported in the generation info.
+	Instance string
+
+	// ProblemType is the type of the problem. Only MDVRPs and
+	// their single-depot special case, the VRP, are supported,
+	// with or without time windows.
+	ProblemType entities.ProblemType
+
+	// Metric measures distances between depots and customers.
+	// Euclidean distances are used if none is provided.
+	Metric entities.Metric
+
+	// BorderlineBound is how much farther (relative to the nearest
+	// depot) a depot may be from a customer and still be a candidate
+	// depot for it. Customers with several candidate depots are
+	// borderline customers. 0 assigns customers to their nearest depot.
+	BorderlineBound float64
+
+	// InitialDNA seeds the initial population, e.g. with a loaded
+	// solution or a saved population snapshot. The remainder of
+	// the population is generated randomly.
+	InitialDNA []DNA
+
+	// Seed seeds the random number generator.
+	// A time-based seed is used if none is provided.
+	Seed int64
+
+	// CheckpointInterval is how many generations pass between
+	// checkpoints written to CheckpointPath. 0 disables checkpoints.
+	CheckpointInterval int
+	CheckpointPath     string
+
 	RandomChanceRouteSplit              int
 	RandomChanceDepotRelocation         int
 	RandomChanceEvaluateOuterDepotRoute int
+	RandomChanceBorderlineRelocation    int
+	RandomChanceVehicleSwap             int
+}
+
+// DefaultConfig returns the tuned parameters used by the solver
+// and benchmark commands. The problem is left to be set.
+func DefaultConfig() SolverConfig {
+	return SolverConfig{
+		PopulationSize:  128,
+		SelectionSize:   0.5,
+		SelectionMethod: Roulette,
+		BorderlineBound: 0.5,
+
+		// 1/n chances:
+		RandomChanceRouteSplit:              20,
+		RandomChanceDepotRelocation:         50,
+		RandomChanceEvaluateOuterDepotRoute: 100000,
+		RandomChanceBorderlineRelocation:    10,
+		RandomChanceVehicleSwap:             20,
+	}
 }
 
 // ValidateAndSetDefaults validates the configuration and sets
@@ -38,7 +112,30 @@ func (cfg *SolverConfig) ValidateAndSetDefaults() error {
 	if len(cfg.Customers) == 0 {
 		return fmt.Errorf("No customers provided")
 	}
+	for _, id := range cfg.Depots.IDs() {
+		vehicles := 0
+		for _, vehicle := range cfg.Depots[id].VehicleTypes() {
+			if vehicle.Count < 0 {
+				return fmt.Errorf("Depot %d has a negative number of vehicles", id)
+			}
+			vehicles += vehicle.Count
+		}
+		if vehicles == 0 {
+			return fmt.Errorf("Depot %d has no vehicles", id)
+		}
+	}
+	if cfg.ProblemType == "" {
+		cfg.ProblemType = entities.MDVRP
+	}
+	switch cfg.ProblemType {
+	case entities.MDVRP, entities.VRP, entities.MDVRPTW, entities.VRPTW:
+	default:
+		return fmt.Errorf("Problem type %s is not supported", cfg.ProblemType)
+	}
 
+	if cfg.Metric == nil {
+		cfg.Metric = entities.Euclidean{}
+	}
 	if cfg.PopulationSize == 0 {
 		cfg.PopulationSize = 200
 	}
@@ -48,6 +145,23 @@ func (cfg *SolverConfig) ValidateAndSetDefaults() error {
 	if cfg.SelectionSize == 0 {
 		cfg.SelectionSize = 0.3
 	}
+	for i, dna := range cfg.InitialDNA {
+		if err := dna.Validate(cfg.Depots, cfg.Customers); err != nil {
+			return fmt.Errorf("Invalid initial DNA %d: %v", i, err)
+		}
+	}
+	if cfg.CheckpointInterval < 0 {
+		return fmt.Errorf("Checkpoint interval cannot be negative")
+	}
+	if cfg.CheckpointInterval > 0 && cfg.CheckpointPath == "" {
+		return fmt.Errorf("No checkpoint path provided")
+	}
+	if cfg.BorderlineBound < 0 {
+		return fmt.Errorf("Borderline bound cannot be negative")
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
 	if cfg.SelectionMethod == "" {
 		cfg.SelectionMethod = Roulette
 	}
@@ -61,6 +175,12 @@ func (cfg *SolverConfig) ValidateAndSetDefaults() error {
 	if cfg.RandomChanceEvaluateOuterDepotRoute == 0 {
 		cfg.RandomChanceEvaluateOuterDepotRoute = 9999999999
 	}
+	if cfg.RandomChanceBorderlineRelocation == 0 {
+		cfg.RandomChanceBorderlineRelocation = 9999999999
+	}
+	if cfg.RandomChanceVehicleSwap == 0 {
+		cfg.RandomChanceVehicleSwap = 9999999999
+	}
 
 	return nil
 }
@@ -70,9 +190,37 @@ type Solver struct {
 	SolverConfig
 	threads *threading.Instance
 
+	grouping   Grouping
+	borderline []int
+
+	// fleets are the vehicle types of each depot.
+	fleets map[int][]entities.VehicleType
+
+	// directed is true if reversing a route may change it.
+	directed bool
+
+	// timeWindows is true if any depot or customer
+	// has a time window to schedule routes by.
+	timeWindows bool
+
 	agents     Agents
 	generation int
+	seed       int64
+	started    time.Time
+
+	// elapsed is how long a restored solver had already run.
+	elapsed time.Duration
+
+	// best is the best agent so far, which
+	// improvements are measured against.
+	best *Agent
+
+	bestKnownCost float64
 
+	observers []Observer
+
+	// PostIterationCallback is called after every generation.
+	// Observers are preferred, as any number can be subscribed.
 	PostIterationCallback func(info GenerationInfo)
 }
 
@@ -82,42 +230,73 @@ func NewSolver(cfg SolverConfig) (*Solver, error) {
 		return nil, err
 	}
 
+	grouping := NewGrouping(cfg.Depots, cfg.Customers, cfg.Metric, cfg.BorderlineBound)
+
+	fleets := map[int][]entities.VehicleType{}
+	for id, depot := range cfg.Depots {
+		fleets[id] = depot.VehicleTypes()
+	}
+
 	return &Solver{
 		SolverConfig:          cfg,
 		PostIterationCallback: func(info GenerationInfo) {},
 		threads:               threading.New(threading.Config{NumThreads: cfg.NumCPUs}),
+		grouping:              grouping,
+		borderline:            grouping.Borderline(),
+		fleets:                fleets,
+		timeWindows:           hasTimeWindows(cfg.Depots, cfg.Customers),
+		directed:              Directed(cfg.Metric, cfg.Depots, cfg.Customers),
+		seed:                  cfg.Seed,
+		bestKnownCost:         problem.BestKnown[cfg.Instance],
 	}, nil
 
 }
 
-// Solve runs the solver and returns a stop-function
-// for external abortion of the process.
-func (s *Solver) Solve(endCondition EndCondition) func() {
-	abort := make(chan bool)
-	go s.solve(endCondition, abort)
+// Solve runs the solver until the end condition is met or the
+// context is done and returns information about the final
+// generation, including the best agent. Cancelling the context
+// stops the solver promptly, also in the middle of a generation.
+// The initial population is always completed, so the returned
+// best agent is never nil, even if the context is already done.
+func (s *Solver) Solve(ctx context.Context, endCondition EndCondition) GenerationInfo {
+	s.started = time.Now().Add(-s.elapsed)
+
+	for _, o := range s.observers {
+		o.OnStart(s.startInfo())
+	}
 
-	return func() {
-		abort <- true
+	// A restored solver already has its population.
+	if len(s.agents) == 0 {
+		s.initializeAgents()
+	}
+
+	info := s.solve(ctx, endCondition)
+	for _, o := range s.observers {
+		o.OnFinish(info)
 	}
-}
 
-func (s *Solver) solve(endCondition EndCondition, abort chan bool) {
-	s.initializeAgents()
+	return info
+}
 
-	for ; ; s.generation++ {
+// solve runs generations until the end condition is
+// met or the context is done.
+func (s *Solver) solve(ctx context.Context, endCondition EndCondition) GenerationInfo {
+	for ; ctx.Err() == nil; s.generation++ {
 		numNewAgents := int(float64(s.PopulationSize) * s.SelectionSize)
+		rngs := s.rngs(s.generation)
 
 		s.threads.Run(func(tid int) error {
-			for i := tid; i < numNewAgents; i += s.threads.NumThreads {
-				p1i, p1 := s.agents.SelectOne(s.SelectionMethod)
-				p2i, p2 := s.agents.SelectOne(s.SelectionMethod)
+			rng := rngs[tid]
+			for i := tid; i < numNewAgents && ctx.Err() == nil; i += s.threads.NumThreads {
+				p1i, p1 := s.agents.SelectOne(s.SelectionMethod, rng)
+				p2i, p2 := s.agents.SelectOne(s.SelectionMethod, rng)
 
-				c1 := s.mate(p1, p2)
+				c1 := s.mate(p1, p2, rng)
 				if c1.Fitness.Total < p1.Fitness.Total {
 					s.agents[p1i] = c1
 				}
 
-				c2 := s.mate(p2, p1)
+				c2 := s.mate(p2, p1, rng)
 				if c2.Fitness.Total < p2.Fitness.Total {
 					s.agents[p2i] = c2
 				}
@@ -126,64 +305,161 @@ func (s *Solver) solve(endCondition EndCondition, abort chan bool) {
 			return nil
 		})
 
-		s.onIterationEnd()
+		// An aborted generation is incomplete and is
+		// therefore not reported.
+		if ctx.Err() != nil {
+			break
+		}
 
-		select {
-		case <-abort:
-			return
-		default:
-			continue
+		info := s.onIterationEnd()
+		if s.best == nil || info.BestAgent.Fitness.improves(s.best.Fitness) {
+			if s.best != nil {
+				improvement := newImprovement(info, s.best, s.Depots, s.directed)
+				for _, o := range s.observers {
+					o.OnImprovement(improvement)
+				}
+			}
+			s.best = info.BestAgent.Copy()
+		}
+		if endCondition.isMet(info) {
+			return info
 		}
 	}
 
+	return s.generationInfo()
 }
 
 // mate is a function for creating an offspring from two
 // parents. the function also runs the random mutation
 // procedure for the child.
-func (s *Solver) mate(a, b *Agent) (child *Agent) {
-	route := b.Dna.GetRandomRoute()
+func (s *Solver) mate(a, b *Agent, rng *rand.Rand) (child *Agent) {
+	route := b.Dna.GetRandomRoute(rng)
 
 	child = a.Copy()
-	child.InjectRoute(route, s)
-	child.RandomMutation(s)
+	child.Operators = []Operator{Crossover}
+	child.InjectRoute(route, s, rng)
+	child.RandomMutation(s, rng)
 
-	child.Evaluate(s.Depots, s.Customers)
+	child.Evaluate(s)
 
 	return
 }
 
 // initializeAgents creates the initial population.
+// Agents are seeded from the initial DNA first, padded with
+// empty routes for unused vehicles, and the rest of the
+// population is created randomly.
 func (s *Solver) initializeAgents() {
-	s.threads.Run(func(tid int) error {
-		agents := Agents{}
-		for i := 0; i < s.PopulationSize/s.NumCPUs; i++ {
-			agents = append(agents, NewAgent(s))
+	for _, dna := range s.InitialDNA {
+		if len(s.agents) == s.PopulationSize {
+			break
 		}
+		agent := (&Agent{Dna: dna, Operators: []Operator{SeededInitialization}}).Copy()
+		agent.Dna = agent.Dna.pad(s.Depots)
+		agent.Evaluate(s)
+		s.agents = append(s.agents, agent)
+	}
 
-		s.threads.Lock()
-		s.agents = append(s.agents, agents...)
-		s.threads.Unlock()
-
+	// Threads' agents are added in thread order,
+	// so that the population does not depend on timing.
+	numRandomAgents := s.PopulationSize - len(s.agents)
+	rngs := s.rngs(initialization)
+	threadAgents := make([]Agents, s.threads.NumThreads)
+	s.threads.Run(func(tid int) error {
+		for i := tid; i < numRandomAgents; i += s.threads.NumThreads {
+			threadAgents[tid] = append(threadAgents[tid], NewAgent(s, rngs[tid]))
+		}
 		return nil
 	})
+	for _, agents := range threadAgents {
+		s.agents = append(s.agents, agents...)
+	}
+}
+
+// SavePopulation writes a snapshot of the current population
+// to the specified filePath. The snapshot can be used as
+// InitialDNA for a later run.
+// It is only safe to call from the PostIterationCallback.
+func (s *Solver) SavePopulation(filePath string) error {
+	return SavePopulation(filePath, s.agents)
 }
 
 // inIterationEnd is called on iteration end and
 // cleans up the generation and runs
 // external metric functions.
-func (s *Solver) onIterationEnd() {
+func (s *Solver) onIterationEnd() GenerationInfo {
+	info := s.generationInfo()
+
+	if s.CheckpointInterval > 0 && (s.generation+1)%s.CheckpointInterval == 0 {
+		info.CheckpointError = SaveCheckpoint(s.CheckpointPath, s.checkpoint(info))
+	}
+
+	s.PostIterationCallback(info)
+	for _, o := range s.observers {
+		o.OnGeneration(info)
+	}
+
+	return info
+}
+
+// generationInfo summarizes the current population.
+func (s *Solver) generationInfo() GenerationInfo {
 	info := GenerationInfo{
-		BestAgent:        s.agents[0],
 		GenerationNumber: s.generation,
+		Elapsed:          time.Since(s.started),
+		BestKnownCost:    s.bestKnownCost,
+	}
+	if len(s.agents) == 0 {
+		return info
 	}
 
+	info.BestAgent = s.agents[0]
+	numFeasible := 0
 	for _, agent := range s.agents {
 		info.PopulationFitness.Add(&agent.Fitness)
+		info.MeanFitness += agent.Fitness.Total
 		if agent.Fitness.Total < info.BestAgent.Fitness.Total {
 			info.BestAgent = agent
 		}
+		if agent.Fitness.Total > info.WorstFitness {
+			info.WorstFitness = agent.Fitness.Total
+		}
+		if agent.Fitness.IsFeasible() {
+			numFeasible++
+		}
 	}
+	info.MeanFitness /= float64(len(s.agents))
+	if info.BestAgent.Fitness.IsFeasible() {
+		info.Gap, _ = problem.Gap(s.Instance, info.BestAgent.Fitness.Distance)
+	}
+	info.FeasibleRatio = float64(numFeasible) / float64(len(s.agents))
 
-	s.PostIterationCallback(info)
+	// Comparing the edges of every agent is costly,
+	// so it is only done if an observer uses the result.
+	frequency, diversity := s.usesEdgeFrequency(), s.usesDiversity()
+	if !frequency && !diversity {
+		return info
+	}
+
+	bestEdges := info.BestAgent.Dna.Edges(s.Depots)
+	if frequency {
+		info.EdgeFrequency = map[Edge]float64{}
+	}
+	for _, agent := range s.agents {
+		edges := agent.Dna.Edges(s.Depots)
+		if diversity {
+			info.Diversity += edgeDistance(edges, bestEdges)
+		}
+		if frequency {
+			for edge := range edges {
+				info.EdgeFrequency[edge]++
+			}
+		}
+	}
+	info.Diversity /= float64(len(s.agents))
+	for edge := range info.EdgeFrequency {
+		info.EdgeFrequency[edge] /= float64(len(s.agents))
+	}
+
+	return info
 }
@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+)
+
+func TestSolveCancelledImmediately(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{PopulationSize: 20, Seed: 1})
+
+	finished := 0
+	s.Subscribe(ObserverFunc(func(info GenerationInfo) {
+		t.Errorf("Unexpected generation %d", info.GenerationNumber)
+	}))
+	s.Subscribe(&finishCounter{n: &finished})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	info := s.Solve(ctx, EndCondition{})
+
+	if info.BestAgent == nil {
+		t.Fatal("Expected a best agent")
+	}
+	if err := info.BestAgent.Dna.Validate(s.Depots, s.Customers); err != nil {
+		t.Fatal(err)
+	}
+	if finished != 1 {
+		t.Errorf("Expected OnFinish once, got %d", finished)
+	}
+}
+
+func TestSeededAgentsArePadded(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{PopulationSize: 2, NumCPUs: 1, Seed: 1})
+
+	// A solution only lists its used routes.
+	dna := DNA{}
+	for _, route := range NewDNA(s.Depots, s.Customers, s.grouping, rand.New(rand.NewSource(1))) {
+		if len(route.Path) > 0 {
+			dna = append(dna, route)
+		}
+	}
+	s.InitialDNA = []DNA{dna}
+	s.initializeAgents()
+
+	numVehicles := 0
+	for _, depot := range s.Depots {
+		numVehicles += depot.MaxNumVehicles
+	}
+	if seeded := s.agents[0].Dna; len(seeded) != numVehicles {
+		t.Errorf("Expected a route for each of the %d vehicles, got %d", numVehicles, len(seeded))
+	}
+}
+
+// finishCounter counts the OnFinish calls it gets.
+type finishCounter struct {
+	BaseObserver
+	n *int
+}
+
+func (fc *finishCounter) OnFinish(info GenerationInfo) {
+	*fc.n++
+}
+
+func TestGapOnlyForFeasibleAgents(t *testing.T) {
+	s := newTestSolver(t, "p01", SolverConfig{Instance: "p01", Seed: 1})
+
+	// A single route per depot overloads its vehicle.
+	routes := map[int]*Route{}
+	for _, cID := range s.Customers.IDs() {
+		depotID := s.grouping[cID][0]
+		if routes[depotID] == nil {
+			routes[depotID] = &Route{DepotID: depotID}
+		}
+		routes[depotID].Path = append(routes[depotID].Path, cID)
+	}
+	infeasible := &Agent{}
+	for _, route := range routes {
+		infeasible.Dna = append(infeasible.Dna, route)
+	}
+	infeasible.Evaluate(s)
+	if infeasible.Fitness.IsFeasible() {
+		t.Fatal("Expected an infeasible agent")
+	}
+
+	s.agents = Agents{infeasible}
+	if info := s.generationInfo(); info.BestKnownCost == 0 || info.Gap != 0 {
+		t.Errorf("Expected no gap to BKS %v, got %v", info.BestKnownCost, info.Gap)
+	}
+
+	s.agents = Agents{NewAgent(s, rand.New(rand.NewSource(1)))}
+	if !s.agents[0].Fitness.IsFeasible() {
+		t.Fatal("Expected a feasible agent")
+	}
+	if info := s.generationInfo(); info.Gap <= 0 {
+		t.Errorf("Expected a positive gap, got %v", info.Gap)
+	}
+}
+
+func TestNewSolverRejectsDepotsWithoutVehicles(t *testing.T) {
+	customers := entities.Customers{1: {ID: 1, X: 1, Y: 1, Demand: 1}}
+	tests := []struct {
+		name  string
+		depot *entities.Depot
+		err   string
+	}{
+		{"no vehicles", &entities.Depot{MaxVehicleLoad: 10}, "Depot 1 has no vehicles"},
+		{"empty fleet", &entities.Depot{Fleet: []entities.VehicleType{{MaxLoad: 10}}}, "Depot 1 has no vehicles"},
+		{"negative count", &entities.Depot{Fleet: []entities.VehicleType{{Count: 2}, {Count: -1}}}, "Depot 1 has a negative number of vehicles"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			depots := entities.Depots{0: {MaxNumVehicles: 1, MaxVehicleLoad: 10}, 1: test.depot}
+			_, err := NewSolver(SolverConfig{Depots: depots, Customers: customers})
+			if err == nil || err.Error() != test.err {
+				t.Errorf("Expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package visualizer
 
 import (
+	"fmt"
 	"math"
 	"sync"
 	"time"
@@ -9,28 +10,75 @@ import (
 	"github.com/tfriedel6/canvas/sdlcanvas"
 
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/render"
 	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
 )
 
 // The visualizer instance constains logic for illustrating a
-// MDVRP graph. The instance is tightly coupled with the solver.
+// MDVRP graph. It is subscribed to the solver as an observer.
+//
+// A stats panel and a convergence sparkline are shown on top, and
+// routes exceeding their load or duration limits are drawn dashed.
+//
+// The view is zoomed with the mouse wheel and panned by dragging.
+// Hovering a customer shows its details, and clicking a customer
+// or route highlights the route. D and C toggle depot and customer
+// labels, Y flips the view upside down and R resets the view.
+//
+// With a reference solution set, M switches between showing the
+// best agent, the best agent and the reference side by side, and
+// the two overlaid. H toggles a heatmap of the edges traveled
+// across the whole population. The solver only calculates them
+// while the heatmap is shown, so it is empty once the run is done.
 type Instance struct {
 	window *sdlcanvas.Window
 	canvas *canvas.Canvas
 
-	depots    entities.Depots
-	customers entities.Customers
-	bestAgent *solver.Agent
+	depots     entities.Depots
+	customers  entities.Customers
+	routeStats func(route *solver.Route) solver.RouteStats
+	directed   bool
+	bestAgent  *solver.Agent
+	info       solver.GenerationInfo
 
-	// bounding box for nodes
-	// large X = max x, small x = min x etc...
-	X, x, Y, y float64
+	reference      *solver.Agent
+	referenceLabel string
+
+	// history is the best fitness of past generations,
+	// thinned out as it grows.
+	history []float64
+
+	// heatmap is toggled from the main loop and
+	// read by the solver through UsesEdgeFrequency.
+	heatmap bool
 
 	mu sync.Mutex
 
+	// Interaction state. It is only used from the main loop,
+	// which also runs the window's event callbacks.
+	view           *render.View
+	mouse          [2]float64
+	dragging       bool
+	dragged        bool
+	hover          int
+	highlight      int
+	depotLabels    bool
+	customerLabels bool
+	flipY          bool
+	mode           mode
+
 	stop chan bool
 }
 
+// mode is what the visualizer shows.
+type mode int
+
+const (
+	showBest mode = iota
+	showSideBySide
+	showOverlay
+)
+
 func New() (*Instance, error) {
 	i := Instance{
 		stop: make(chan bool),
@@ -43,33 +91,73 @@ func New() (*Instance, error) {
 	i.window = wnd
 	i.canvas = cv
 
+	wnd.MouseWheel = i.onMouseWheel
+	wnd.MouseDown = i.onMouseDown
+	wnd.MouseMove = i.onMouseMove
+	wnd.MouseUp = i.onMouseUp
+	wnd.KeyDown = i.onKeyDown
+
 	return &i, nil
 }
 
-func (i *Instance) Draw(depots entities.Depots, customers entities.Customers, best *solver.Agent) {
+// OnStart shows the problem being solved.
+func (i *Instance) OnStart(info solver.StartInfo) {
 	i.mu.Lock()
-	i.customers = customers
-	i.depots = depots
-	i.bestAgent = best
+	i.depots = info.Depots
+	i.customers = info.Customers
+	i.routeStats = info.RouteStats
+	i.directed = info.Directed
 	i.mu.Unlock()
+}
 
-	locations := []entities.Location{}
-	for _, item := range customers {
-		locations = append(locations, item)
-	}
-	for _, item := range depots {
-		locations = append(locations, item)
+// SetReference sets a reference solution to compare
+// the best agent with, e.g. a best-known solution.
+func (i *Instance) SetReference(agent *solver.Agent, label string) {
+	i.mu.Lock()
+	i.reference = agent
+	i.referenceLabel = label
+	i.mu.Unlock()
+}
+
+// maxHistory is the number of sparkline points kept.
+const maxHistory = 500
+
+// OnGeneration shows the generation's best agent.
+func (i *Instance) OnGeneration(info solver.GenerationInfo) {
+	if info.BestAgent == nil {
+		return
 	}
 
-	X, x, Y, y := getBoundary(locations)
+	i.mu.Lock()
+	i.bestAgent = info.BestAgent
+	i.info = info
 
-	// Apply 20px padding to all sides.
-	i.X = X + 20
-	i.x = x - 20
-	i.Y = Y + 20
-	i.y = y - 20
+	i.history = append(i.history, info.BestAgent.Fitness.Total)
+	if len(i.history) > maxHistory {
+		thinned := []float64{}
+		for j, v := range i.history {
+			if j%2 == 0 || j == len(i.history)-1 {
+				thinned = append(thinned, v)
+			}
+		}
+		i.history = thinned
+	}
+	i.mu.Unlock()
+}
+
+// UsesEdgeFrequency returns true while the heatmap is shown.
+func (i *Instance) UsesEdgeFrequency() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.heatmap
 }
 
+// OnImprovement does nothing, as every generation is shown.
+func (i *Instance) OnImprovement(improvement solver.Improvement) {}
+
+// OnFinish does nothing, as the last generation stays shown.
+func (i *Instance) OnFinish(info solver.GenerationInfo) {}
+
 func (i *Instance) Run() {
 	i.window.MainLoop(func() {
 		if i.bestAgent == nil {
@@ -78,94 +166,352 @@ func (i *Instance) Run() {
 		}
 
 		i.mu.Lock()
-		w, h := float64(i.canvas.Width()), float64(i.canvas.Height())
 		customers := i.customers
 		depots := i.depots
 		bestAgent := i.bestAgent
+		info := i.info
+		history := append([]float64{}, i.history...)
+		routeStats := i.routeStats
+		reference, referenceLabel := i.reference, i.referenceLabel
+		heatmap := i.heatmap
+		directed := i.directed
 		i.mu.Unlock()
 
-		i.canvas.SetFillStyle("#15202e")
-		i.canvas.FillRect(0, 0, w, h)
-
-		// Draw customers
-		i.canvas.SetStrokeStyle("#FFF5")
-		i.canvas.SetLineWidth(2)
-		for _, customer := range customers {
-			i.canvas.BeginPath()
-			// i.canvas.Arc((customer.X-i.x)*(w/(i.X-i.x)), (customer.Y-i.y)*(h/(i.Y-i.y)), 1, 0, math.Pi*2, false)
-			x, y := i.scaledPosition(customer.GetPosition())
-			i.canvas.Arc(x, y, 1, 0, math.Pi*2, false)
-			i.canvas.Stroke()
-		}
-
-		// Draw depots
-		i.canvas.SetStrokeStyle("#FFF")
-		i.canvas.SetLineWidth(4)
-		for _, depot := range depots {
-			i.canvas.BeginPath()
-			// i.canvas.Arc((depot.X-i.x)*(w/(i.X-i.x)), (depot.Y-i.y)*(h/(i.Y-i.y)), 3, 0, math.Pi*2, false)
-			x, y := i.scaledPosition(depot.GetPosition())
-			i.canvas.Arc(x, y, 1, 0, math.Pi*2, false)
-			i.canvas.Stroke()
-		}
-
-		// Draw agents
-		i.canvas.SetLineWidth(1)
-		for routeID, route := range bestAgent.Dna {
-			color, err := scalarToColor(float64(routeID) / float64(len(bestAgent.Dna)))
-			if err != nil {
-				panic(err)
-			}
-			i.canvas.SetStrokeStyle(color.String())
-
-			depot := depots[route.DepotID]
-			prevX, prevY := i.scaledPosition(depot.GetPosition())
-			i.canvas.BeginPath()
-			i.canvas.MoveTo(prevX, prevY)
-			for _, cID := range route.Path {
-				customer := customers[cID]
-				i.canvas.LineTo(i.scaledPosition(customer.GetPosition()))
-			}
-			i.canvas.LineTo(i.scaledPosition(depot.GetPosition()))
-			i.canvas.ClosePath()
-			i.canvas.Stroke()
+		painter := render.CanvasPainter{Canvas: i.canvas}
+		w, h := painter.Size()
+		if i.view == nil {
+			view := render.NewView(depots, customers, w, h)
+			view.FlipY = i.flipY
+			i.view = &view
+		} else if vw, vh := i.view.Size(); vw != w || vh != h {
+			// The window was resized.
+			view := i.view.Resize(w, h)
+			i.view = &view
+		}
+
+		if heatmap {
+			render.Heatmap{
+				Depots:    depots,
+				Customers: customers,
+				Frequency: info.EdgeFrequency,
+				View:      i.view,
+			}.Draw(painter)
+			i.checkStop()
+			return
 		}
 
-		select {
-		case <-i.stop:
-			i.window.Close()
-		default:
+		if reference != nil && i.mode != showBest {
+			render.Comparison{
+				Depots:    depots,
+				Customers: customers,
+				A:         bestAgent,
+				B:         reference,
+				LabelA:    "Best",
+				LabelB:    referenceLabel,
+				Directed:  directed,
+				Overlay:   i.mode == showOverlay,
+				View:      i.view,
+			}.Draw(painter)
+			i.checkStop()
 			return
 		}
+
+		scene := render.Scene{
+			Depots:         depots,
+			Customers:      customers,
+			Agent:          bestAgent,
+			View:           i.view,
+			DepotLabels:    i.depotLabels,
+			CustomerLabels: i.customerLabels,
+		}
+		if i.highlight != 0 {
+			scene.Highlight, _ = bestAgent.Dna.FindCustomer(i.highlight)
+		}
+		scene.Stats = routeStats
+		scene.Draw(painter)
+
+		render.Caption(painter, statsDetails(info)...)
+		render.Sparkline(painter, w-210, 10, 200, 60, history, "#4CF")
+
+		if scene.Highlight != nil && routeStats != nil {
+			render.Tooltip(painter, 0, h, routeDetails(depots, routeStats(scene.Highlight), scene.Highlight)...)
+		}
+		if customer, ok := customers[i.hover]; ok {
+			render.Tooltip(painter, i.mouse[0], i.mouse[1], customerDetails(customer, bestAgent)...)
+		}
+
+		i.checkStop()
 	})
 }
 
+// checkStop closes the window if the visualizer is stopped.
+func (i *Instance) checkStop() {
+	select {
+	case <-i.stop:
+		i.window.Close()
+	default:
+		return
+	}
+}
+
 func (i *Instance) Stop() {
 	i.stop <- true
 }
 
-func (i *Instance) scaledPosition(_x, _y float64) (float64, float64) {
-	w, h := float64(i.canvas.Width()), float64(i.canvas.Height())
-	return (_x - i.x) * (w / (i.X - i.x)), (_y - i.y) * (h / (i.Y - i.y))
+// canvasPosition converts a window position to a canvas
+// position, which differ on high-DPI displays.
+func (i *Instance) canvasPosition(x, y int) (float64, float64) {
+	w, h := i.window.Size()
+	fw, fh := i.window.FramebufferSize()
+	if w == 0 || h == 0 {
+		return float64(x), float64(y)
+	}
+	return float64(x) * float64(fw) / float64(w), float64(y) * float64(fh) / float64(h)
+}
+
+func (i *Instance) onMouseWheel(_, y int) {
+	if i.view == nil || y == 0 {
+		return
+	}
+	view := i.view.Zoom(math.Pow(1.1, float64(y)), i.mouse[0], i.mouse[1])
+	i.view = &view
+}
+
+func (i *Instance) onMouseDown(button, x, y int) {
+	if button != 1 {
+		return
+	}
+	i.dragging = true
+	i.dragged = false
+	i.mouse[0], i.mouse[1] = i.canvasPosition(x, y)
+}
+
+func (i *Instance) onMouseMove(x, y int) {
+	sx, sy := i.canvasPosition(x, y)
+	if i.dragging && i.view != nil && (sx != i.mouse[0] || sy != i.mouse[1]) {
+		view := i.view.Pan(sx-i.mouse[0], sy-i.mouse[1])
+		i.view = &view
+		i.dragged = true
+	}
+	i.mouse[0], i.mouse[1] = sx, sy
+
+	i.hover = 0
+	if !i.dragging {
+		i.hover = i.customerAt(sx, sy)
+	}
+}
+
+func (i *Instance) onMouseUp(button, x, y int) {
+	if button != 1 {
+		return
+	}
+	if i.dragging && !i.dragged {
+		i.highlight = i.routeAt(i.canvasPosition(x, y))
+	}
+	i.dragging = false
+}
+
+func (i *Instance) onKeyDown(_ int, _ rune, name string) {
+	switch name {
+	case "KeyD":
+		i.depotLabels = !i.depotLabels
+	case "KeyC":
+		i.customerLabels = !i.customerLabels
+	case "KeyY":
+		i.flipY = !i.flipY
+		if i.view != nil {
+			view := i.view.Flip()
+			i.view = &view
+		}
+	case "KeyH":
+		i.mu.Lock()
+		i.heatmap = !i.heatmap
+		i.mu.Unlock()
+	case "KeyM":
+		i.mode = (i.mode + 1) % 3
+	case "KeyR":
+		i.view = nil
+		i.highlight = 0
+	}
 }
 
-func getBoundary(locations []entities.Location) (X float64, x float64, Y float64, y float64) {
-	X, Y = locations[0].GetPosition()
-	x, y = X, Y
+// customerAt returns the ID of the customer closest to the
+// canvas position, or 0 if there is no customer nearby.
+func (i *Instance) customerAt(sx, sy float64) int {
+	if i.view == nil {
+		return 0
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
-	for _, location := range locations {
-		_x, _y := location.GetPosition()
-		if X < _x {
-			X = _x
-		} else if x > _x {
-			x = _x
+	closest, closestDistance := 0, 8.0
+	for id, customer := range i.customers {
+		x, y := i.view.Position(customer.GetPosition())
+		if d := math.Hypot(x-sx, y-sy); d < closestDistance {
+			closest, closestDistance = id, d
 		}
-		if Y < _y {
-			Y = _y
-		} else if y > _y {
-			y = _y
+	}
+	return closest
+}
+
+// routeAt returns the ID of a customer in the route closest to
+// the canvas position, or 0 if there is no route nearby. A route
+// is found by clicking one of its customers or lines.
+func (i *Instance) routeAt(sx, sy float64) int {
+	if cID := i.customerAt(sx, sy); cID != 0 {
+		return cID
+	}
+	if i.view == nil {
+		return 0
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.bestAgent == nil {
+		return 0
+	}
+
+	closest, closestDistance := 0, 5.0
+	for _, route := range i.bestAgent.Dna {
+		if len(route.Path) == 0 {
+			continue
+		}
+
+		points := [][2]float64{}
+		x, y := i.view.Position(i.depots[route.DepotID].GetPosition())
+		points = append(points, [2]float64{x, y})
+		for _, cID := range route.Path {
+			x, y := i.view.Position(i.customers[cID].GetPosition())
+			points = append(points, [2]float64{x, y})
+		}
+		if !i.depots[route.DepotID].OpenRoutes {
+			points = append(points, points[0])
+		}
+
+		for j := 0; j < len(points)-1; j++ {
+			if d := segmentDistance(points[j], points[j+1], [2]float64{sx, sy}); d < closestDistance {
+				closest, closestDistance = route.Path[0], d
+			}
 		}
 	}
+	return closest
+}
 
-	return
+// segmentDistance returns the distance from p to the line segment a-b.
+func segmentDistance(a, b, p [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/length))
+	}
+	return math.Hypot(a[0]+t*dx-p[0], a[1]+t*dy-p[1])
+}
+
+// customerDetails describes the customer and the route serving it.
+func customerDetails(customer *entities.Customer, agent *solver.Agent) []string {
+	title := fmt.Sprintf("Customer %d", customer.ID)
+	if customer.Name != "" {
+		title += " (" + customer.Name + ")"
+	}
+	lines := []string{
+		title,
+		fmt.Sprintf("Demand:       %.2f", customer.Demand),
+		fmt.Sprintf("Service time: %.2f", customer.ServiceDuration),
+	}
+	if customer.TimeWindow != nil {
+		lines = append(lines, fmt.Sprintf("Time window:  %v", customer.TimeWindow))
+	}
+
+	route, _ := agent.Dna.FindCustomer(customer.ID)
+	if route == nil {
+		return append(lines, "Not served")
+	}
+	vehicle := 0
+	for _, r := range agent.Dna {
+		if r.DepotID == route.DepotID && len(r.Path) > 0 {
+			vehicle++
+		}
+		if r == route {
+			break
+		}
+	}
+	return append(lines, fmt.Sprintf("Route:        depot %d, vehicle %d", route.DepotID, vehicle))
+}
+
+// statsDetails describes the generation.
+func statsDetails(info solver.GenerationInfo) []string {
+	best := info.BestAgent.Fitness
+	feasible := "no"
+	if best.IsFeasible() {
+		feasible = "yes"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Generation:   %d", info.GenerationNumber),
+		fmt.Sprintf("Best fitness: %.2f", best.Total),
+		fmt.Sprintf("Mean fitness: %.2f", info.MeanFitness),
+		fmt.Sprintf("Distance:     %.2f", best.Distance),
+		fmt.Sprintf("Over-demand:  %.2f", best.OverDemand),
+	}
+	if best.Cost != best.Distance {
+		lines = append(lines, fmt.Sprintf("Cost:         %.2f", best.Cost))
+	}
+	if best.TimeWindowViolation > 0 {
+		lines = append(lines, fmt.Sprintf("Lateness:     %.2f", best.TimeWindowViolation))
+	}
+	if best.OverDuration > 0 {
+		lines = append(lines, fmt.Sprintf("Overtime:     %.2f", best.OverDuration))
+	}
+	lines = append(lines, fmt.Sprintf("Feasible:     %s (%.0f%% of population)", feasible, info.FeasibleRatio*100))
+	if info.BestKnownCost > 0 {
+		gap := "- (infeasible)"
+		if best.IsFeasible() {
+			gap = fmt.Sprintf("%.2f%%", info.Gap)
+		}
+		lines = append(lines, "Gap to BKS:   "+gap)
+	}
+	return lines
+}
+
+// routeDetails describes the route's vehicle, load, distance and duration.
+func routeDetails(depots entities.Depots, stats solver.RouteStats, route *solver.Route) []string {
+	depot := depots[route.DepotID]
+	vehicle := depot.VehicleTypes()[route.VehicleType]
+
+	duration := fmt.Sprintf("Duration:  %.2f", stats.Duration)
+	if vehicle.MaxRouteDuration > 0 {
+		duration += fmt.Sprintf(" / %.2f", vehicle.MaxRouteDuration)
+	}
+	lines := []string{
+		fmt.Sprintf("Route from depot %d", route.DepotID),
+		fmt.Sprintf("Customers: %d", len(route.Path)),
+		fmt.Sprintf("Load:      %.2f / %.2f", stats.Load, vehicle.MaxLoad),
+		fmt.Sprintf("Distance:  %.2f", stats.Distance),
+		duration,
+	}
+	if depot.Fleet != nil {
+		name := vehicle.Name
+		if name == "" {
+			name = fmt.Sprintf("type %d", route.VehicleType)
+		}
+		lines = append(lines,
+			fmt.Sprintf("Vehicle:   %s", name),
+			fmt.Sprintf("Cost:      %.2f", stats.Cost),
+		)
+	}
+	if stats.Wait > 0 {
+		lines = append(lines, fmt.Sprintf("Waiting:   %.2f", stats.Wait))
+	}
+	if stats.OverLoad > 0 {
+		lines = append(lines, fmt.Sprintf("Over load by %.2f", stats.OverLoad))
+	}
+	if stats.OverDuration > 0 {
+		lines = append(lines, fmt.Sprintf("Over duration by %.2f", stats.OverDuration))
+	}
+	if stats.Lateness > 0 {
+		lines = append(lines, fmt.Sprintf("Late by %.2f", stats.Lateness))
+	}
+	return lines
 }
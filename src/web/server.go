@@ -0,0 +1,241 @@
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+//go:embed index.html
+var index []byte
+
+// maxHistory is the number of chart points kept. Older
+// points are thinned out as the history grows.
+const maxHistory = 1000
+
+// Server is a live visualizer served over HTTP. The page draws
+// the best solution and a convergence chart, and receives each
+// generation as Server-Sent Events. It is subscribed to the
+// solver as an observer.
+type Server struct {
+	solver.BaseObserver
+
+	problem []byte
+	history []point
+	latest  []byte
+
+	// clients holds a channel per connected event stream.
+	clients map[chan []byte]bool
+
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// location is a depot or customer on the page.
+// Open is true for depots whose routes are open.
+type location struct {
+	ID   int     `json:"id"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Open bool    `json:"open,omitempty"`
+}
+
+// route is a route on the page. Path holds customer IDs.
+type route struct {
+	DepotID int   `json:"depot_id"`
+	Path    []int `json:"path"`
+}
+
+// point is a point in the convergence chart.
+type point struct {
+	Generation int     `json:"generation"`
+	Best       float64 `json:"best"`
+	Mean       float64 `json:"mean"`
+}
+
+// generation is the event sent after every generation.
+type generation struct {
+	point
+	Distance     float64  `json:"distance"`
+	OverDemand   float64  `json:"over_demand"`
+	Lateness     float64  `json:"lateness"`
+	OverDuration float64  `json:"over_duration"`
+	Gap          *float64 `json:"gap,omitempty"`
+	Routes       []route  `json:"routes"`
+}
+
+// NewServer creates a server visualizing the depots and customers.
+func NewServer(depots entities.Depots, customers entities.Customers) (*Server, error) {
+	problem := struct {
+		Depots    []location `json:"depots"`
+		Customers []location `json:"customers"`
+	}{[]location{}, []location{}}
+
+	for id, depot := range depots {
+		problem.Depots = append(problem.Depots, location{ID: id, X: depot.X, Y: depot.Y, Open: depot.OpenRoutes})
+	}
+	for id, customer := range customers {
+		problem.Customers = append(problem.Customers, location{ID: id, X: customer.X, Y: customer.Y})
+	}
+	sort.Slice(problem.Depots, func(i, j int) bool { return problem.Depots[i].ID < problem.Depots[j].ID })
+	sort.Slice(problem.Customers, func(i, j int) bool { return problem.Customers[i].ID < problem.Customers[j].ID })
+
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		problem: data,
+		clients: make(map[chan []byte]bool),
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/events", s.handleEvents)
+
+	return s, nil
+}
+
+// OnGeneration publishes the generation.
+func (s *Server) OnGeneration(info solver.GenerationInfo) {
+	s.Publish(info)
+}
+
+// Publish sends the generation to all connected pages. It never
+// blocks on slow clients; they skip to the latest generation.
+// Generations without a best agent are not sent.
+func (s *Server) Publish(info solver.GenerationInfo) {
+	if info.BestAgent == nil {
+		return
+	}
+
+	g := generation{
+		point: point{
+			Generation: info.GenerationNumber,
+			Best:       info.BestAgent.Fitness.Total,
+			Mean:       info.MeanFitness,
+		},
+		Distance:     info.BestAgent.Fitness.Distance,
+		OverDemand:   info.BestAgent.Fitness.OverDemand,
+		Lateness:     info.BestAgent.Fitness.TimeWindowViolation,
+		OverDuration: info.BestAgent.Fitness.OverDuration,
+		Routes:       []route{},
+	}
+	// Infeasible agents have no gap.
+	if info.BestKnownCost > 0 && info.BestAgent.Fitness.IsFeasible() {
+		gap := info.Gap
+		g.Gap = &gap
+	}
+	for _, r := range info.BestAgent.Dna {
+		if len(r.Path) == 0 {
+			continue
+		}
+		g.Routes = append(g.Routes, route{DepotID: r.DepotID, Path: append([]int{}, r.Path...)})
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, g.point)
+	if len(s.history) > maxHistory {
+		s.history = thin(s.history)
+	}
+	s.latest = data
+	for client := range s.clients {
+		send(client, data)
+	}
+}
+
+// thin keeps every other point, and always the last point.
+func thin(points []point) []point {
+	thinned := []point{}
+	for i, p := range points {
+		if i%2 == 0 || i == len(points)-1 {
+			thinned = append(thinned, p)
+		}
+	}
+	return thinned
+}
+
+// send replaces any unsent event in the client's channel.
+func send(client chan []byte, data []byte) {
+	select {
+	case <-client:
+	default:
+	}
+	client <- data
+}
+
+// ServeHTTP serves the page and its event stream.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(index)
+}
+
+// handleEvents streams the problem and the convergence history
+// so far, followed by every new generation.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan []byte, 1)
+
+	s.mu.Lock()
+	history, err := json.Marshal(s.history)
+	if s.latest != nil {
+		client <- s.latest
+	}
+	s.clients[client] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "event: problem\ndata: %s\n\n", s.problem)
+	fmt.Fprintf(w, "event: history\ndata: %s\n\n", history)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-client:
+			if _, err := fmt.Fprintf(w, "event: generation\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
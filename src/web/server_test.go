@@ -0,0 +1,142 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/entities"
+	"github.com/jorgenhanssen/go-genetic-mdvrp/src/solver"
+)
+
+// event is a Server-Sent Event.
+type event struct {
+	name, data string
+}
+
+// readEvent reads the next event of the stream.
+func readEvent(t *testing.T, r *bufio.Reader) event {
+	var e event
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return e
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func newTestInfo(gen int) solver.GenerationInfo {
+	return solver.GenerationInfo{
+		GenerationNumber: gen,
+		MeanFitness:      200,
+		BestAgent: &solver.Agent{
+			Fitness: solver.Fitness{Total: 100, Distance: 90},
+			Dna:     solver.DNA{{DepotID: 0, Path: []int{2, 1}}, {DepotID: 0}},
+		},
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	depots := entities.Depots{0: {X: 1, Y: 2, OpenRoutes: true}}
+	customers := entities.Customers{2: {ID: 2, X: 5, Y: 6}, 1: {ID: 1, X: 3, Y: 4}}
+	s, err := NewServer(depots, customers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Publish(solver.GenerationInfo{GenerationNumber: 0})
+	s.Publish(newTestInfo(1))
+	s.Publish(newTestInfo(2))
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	res, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected an event stream, got %q", ct)
+	}
+	stream := bufio.NewReader(res.Body)
+
+	want := []event{
+		{"problem", `{"depots":[{"id":0,"x":1,"y":2,"open":true}],"customers":[{"id":1,"x":3,"y":4},{"id":2,"x":5,"y":6}]}`},
+		// Generations without a best agent are not sent.
+		{"history", `[{"generation":1,"best":100,"mean":200},{"generation":2,"best":100,"mean":200}]`},
+		{"generation", `{"generation":2,"best":100,"mean":200,"distance":90,"over_demand":0,"lateness":0,"over_duration":0,"routes":[{"depot_id":0,"path":[2,1]}]}`},
+	}
+	for _, w := range want {
+		if e := readEvent(t, stream); e != w {
+			t.Errorf("Expected %v, got %v", w, e)
+		}
+	}
+
+	// A client that does not read must not block publishing.
+	const last = 10000
+	published := make(chan bool)
+	go func() {
+		for gen := 3; gen <= last; gen++ {
+			s.Publish(newTestInfo(gen))
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Publishing blocked on a slow client")
+	}
+
+	// The client catches up to the latest generation.
+	previous := 2
+	for previous != last {
+		e := readEvent(t, stream)
+		var g generation
+		if err := json.Unmarshal([]byte(e.data), &g); err != nil {
+			t.Fatal(err)
+		}
+		if e.name != "generation" || g.Generation <= previous {
+			t.Fatalf("Expected a generation after %d, got %s %d", previous, e.name, g.Generation)
+		}
+		previous = g.Generation
+	}
+}
+
+func TestSendKeepsLatest(t *testing.T) {
+	client := make(chan []byte, 1)
+	send(client, []byte("1"))
+	send(client, []byte("2"))
+
+	if data := string(<-client); data != "2" {
+		t.Errorf("Expected the latest event, got %s", data)
+	}
+}
+
+func TestServerHistoryIsThinned(t *testing.T) {
+	s, err := NewServer(entities.Depots{}, entities.Customers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for gen := 0; gen < 5*maxHistory; gen++ {
+		s.Publish(newTestInfo(gen))
+	}
+
+	if len(s.history) > maxHistory {
+		t.Errorf("Expected at most %d points, got %d", maxHistory, len(s.history))
+	}
+	if last := s.history[len(s.history)-1].Generation; last != 5*maxHistory-1 {
+		t.Errorf("Expected the last point to be the latest generation, got %d", last)
+	}
+}